@@ -5,7 +5,10 @@ import (
 	"os"
 
 	"project-exchange/internal/database"
+	"project-exchange/internal/jobs"
+	"project-exchange/internal/mailer"
 	"project-exchange/internal/routes"
+	"project-exchange/internal/webhooks"
 
 	"github.com/joho/godotenv"
 )
@@ -50,6 +53,18 @@ func main() {
 	// Initialize database
 	database.InitDatabase(os.Getenv("DB_PATH"))
 
+	// Initialize mailer
+	mailer.InitMailer()
+
+	// Purge soft-deleted projects and users past their retention window
+	jobs.StartPurger(database.GetDB())
+
+	// Deliver queued webhook events in the background
+	webhooks.InitDispatcher(database.GetDB())
+
+	// Send email digests built from the outbox
+	startScheduler(database.GetDB())
+
 	// Setup routes
 	router := routes.SetupRoutes()
 
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"project-exchange/internal/config"
+	"project-exchange/internal/digest"
+	"project-exchange/internal/mailer"
+
+	"gorm.io/gorm"
+)
+
+// startScheduler sends due email digests every DIGEST_INTERVAL and prunes
+// outbox events no digest needs any more.
+func startScheduler(db *gorm.DB) {
+	interval := config.Duration("DIGEST_INTERVAL", 15*time.Minute)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			now := time.Now()
+			if sent, err := digest.SendDue(db, mailer.GetMailer(), now); err != nil {
+				log.Println("Failed to send digests:", err)
+			} else if sent > 0 {
+				log.Printf("Sent %d digests", sent)
+			}
+
+			if err := digest.Prune(db, now); err != nil {
+				log.Println("Failed to prune outbox events:", err)
+			}
+
+			<-ticker.C
+		}
+	}()
+}
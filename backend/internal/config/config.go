@@ -0,0 +1,61 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// String returns the environment variable or the fallback when it is unset.
+func String(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// Duration parses a Go duration such as "15m" or "720h" from the environment.
+func Duration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid duration in %s=%q, using %s", key, value, fallback)
+		return fallback
+	}
+	return d
+}
+
+// Bool parses a boolean such as "true" or "1" from the environment.
+func Bool(key string, fallback bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Invalid boolean in %s=%q, using %t", key, value, fallback)
+		return fallback
+	}
+	return b
+}
+
+// Int parses an integer from the environment.
+func Int(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid integer in %s=%q, using %d", key, value, fallback)
+		return fallback
+	}
+	return i
+}
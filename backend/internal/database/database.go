@@ -19,17 +19,62 @@ func InitDatabase(dbPath string) {
 		log.Fatal("Failed to connect to database:", err)
 	}
 
+	// Checked before migrating, which adds the column
+	hadVerifiedAt := DB.Migrator().HasColumn(&models.User{}, "VerifiedAt")
+
 	// Auto migrate the schemas
 	err = DB.AutoMigrate(
 		&models.User{},
 		&models.Project{},
 		&models.ProjectMember{},
 		&models.Message{},
+		&models.MessageEdit{},
+		&models.MessageReaction{},
+		&models.ProjectEvent{},
+		&models.MemberStatusChange{},
+		&models.ProjectQuestion{},
+		&models.ProjectPosition{},
+		&models.ApplicationAnswer{},
+		&models.RefreshToken{},
+		&models.PasswordReset{},
+		&models.Skill{},
+		&models.SkillAlias{},
+		&models.UserSkill{},
+		&models.Invitation{},
+		&models.InviteLink{},
+		&models.OwnershipTransfer{},
+		&models.Notification{},
+		&models.NotificationMute{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+		&models.OutboxEvent{},
 	)
 	if err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
 
+	// Existing accounts predate email verification
+	if !hadVerifiedAt {
+		if err := backfillVerifiedAt(DB); err != nil {
+			log.Fatal("Failed to backfill email verification:", err)
+		}
+	}
+
+	// Full-text search index for projects
+	if err := setupProjectSearch(DB); err != nil {
+		log.Fatal("Failed to set up project search:", err)
+	}
+
+	// Skills taxonomy and conversion of legacy free-text skills
+	if err := setupSkills(DB); err != nil {
+		log.Fatal("Failed to set up skills:", err)
+	}
+
+	// Placeholder account for the content of deleted users
+	if err := setupDeletedUser(DB); err != nil {
+		log.Fatal("Failed to set up deleted user placeholder:", err)
+	}
+
 	log.Println("Database connected and migrated successfully")
 }
 
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"project-exchange/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// setupDeletedUser creates the placeholder account that deleted users'
+// messages and history are reassigned to. It is marked as a system account
+// and only ever looked up by that mark, so nobody can take it over by
+// registering its address. It can't log in: its password hash is not a valid
+// bcrypt hash.
+func setupDeletedUser(db *gorm.DB) error {
+	var count int64
+	if err := db.Unscoped().Model(&models.User{}).Where("is_system = ?", true).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	// Databases from before the system mark may already have the placeholder,
+	// recognisable by its unusable password hash
+	var existing models.User
+	err := db.Unscoped().Where("email = ?", models.DeletedUserEmail).First(&existing).Error
+	switch {
+	case err == nil && existing.PasswordHash == "!":
+		return db.Unscoped().Model(&existing).Updates(map[string]interface{}{"is_system": true, "available": false}).Error
+	case err == nil:
+		log.Printf("User %d holds the reserved address %s; creating the placeholder account under another address",
+			existing.ID, models.DeletedUserEmail)
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return err
+	}
+
+	email := models.DeletedUserEmail
+	if err == nil {
+		email = fmt.Sprintf("deleted-user-%d@%s", time.Now().Unix(), models.ReservedEmailDomain)
+	}
+
+	placeholder := models.User{Name: "Deleted user", Email: email, PasswordHash: "!", IsSystem: true}
+	if err := db.Create(&placeholder).Error; err != nil {
+		return err
+	}
+	// Keep it out of candidate suggestions
+	return db.Model(&placeholder).Update("available", false).Error
+}
+
+// DeletedUser returns the placeholder account created at startup.
+func DeletedUser(db *gorm.DB) (models.User, error) {
+	var user models.User
+	err := db.Where("is_system = ?", true).Order("id").First(&user).Error
+	return user, err
+}
+
+// ReassignToDeletedUser moves a user's messages and history entries to the
+// placeholder account so they keep loading with an author.
+func ReassignToDeletedUser(tx *gorm.DB, userID uint) error {
+	placeholder, err := DeletedUser(tx)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Model(&models.Message{}).Where("user_id = ?", userID).Update("user_id", placeholder.ID).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Message{}).Where("pinned_by_id = ?", userID).Update("pinned_by_id", placeholder.ID).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Message{}).Where("removed_by_id = ?", userID).Update("removed_by_id", placeholder.ID).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.ProjectEvent{}).Where("actor_id = ?", userID).Update("actor_id", placeholder.ID).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.MemberStatusChange{}).Where("actor_id = ?", userID).Update("actor_id", placeholder.ID).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.Notification{}).Where("actor_id = ?", userID).Update("actor_id", placeholder.ID).Error
+}
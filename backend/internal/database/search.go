@@ -0,0 +1,40 @@
+package database
+
+import "gorm.io/gorm"
+
+// projectSearchSchema creates an external-content FTS5 index over projects and
+// the triggers that keep it in sync with inserts, updates and deletes. Soft
+// deletes are updates, so soft-deleted projects stay indexed and are filtered
+// out at query time.
+var projectSearchSchema = []string{
+	`CREATE VIRTUAL TABLE IF NOT EXISTS projects_fts USING fts5(
+		title, description, category,
+		content='projects', content_rowid='id',
+		tokenize='unicode61 remove_diacritics 2'
+	)`,
+	`CREATE TRIGGER IF NOT EXISTS projects_fts_insert AFTER INSERT ON projects BEGIN
+		INSERT INTO projects_fts(rowid, title, description, category)
+		VALUES (new.id, new.title, new.description, new.category);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS projects_fts_delete AFTER DELETE ON projects BEGIN
+		INSERT INTO projects_fts(projects_fts, rowid, title, description, category)
+		VALUES ('delete', old.id, old.title, old.description, old.category);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS projects_fts_update AFTER UPDATE ON projects BEGIN
+		INSERT INTO projects_fts(projects_fts, rowid, title, description, category)
+		VALUES ('delete', old.id, old.title, old.description, old.category);
+		INSERT INTO projects_fts(rowid, title, description, category)
+		VALUES (new.id, new.title, new.description, new.category);
+	END`,
+	// Rebuilding picks up rows written before the index existed
+	`INSERT INTO projects_fts(projects_fts) VALUES ('rebuild')`,
+}
+
+func setupProjectSearch(db *gorm.DB) error {
+	for _, stmt := range projectSearchSchema {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
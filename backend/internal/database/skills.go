@@ -0,0 +1,52 @@
+package database
+
+import (
+	"slices"
+	"strings"
+
+	"project-exchange/internal/models"
+	"project-exchange/internal/skills"
+
+	"gorm.io/gorm"
+)
+
+// setupSkills seeds the skills taxonomy and converts the free-text skills of
+// users who have no structured skills yet.
+func setupSkills(db *gorm.DB) error {
+	if err := skills.Seed(db); err != nil {
+		return err
+	}
+
+	var users []models.User
+	err := db.Where("skills <> ''").
+		Where("NOT EXISTS (SELECT 1 FROM user_skills WHERE user_skills.user_id = users.id)").
+		Find(&users).Error
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var names []string
+			for _, name := range skills.ParseList(user.Skills) {
+				skill, err := skills.Resolve(tx, name)
+				if err != nil {
+					return err
+				}
+
+				userSkill := models.UserSkill{UserID: user.ID, SkillID: skill.ID}
+				if err := tx.Where(userSkill).FirstOrCreate(&userSkill).Error; err != nil {
+					return err
+				}
+				if !slices.Contains(names, skill.Name) {
+					names = append(names, skill.Name)
+				}
+			}
+			return tx.Model(&user).Update("skills", strings.Join(names, ", ")).Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package database
+
+import (
+	"project-exchange/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// backfillVerifiedAt treats the accounts that existed before email
+// verification as verified, so turning on REQUIRE_EMAIL_VERIFICATION does not
+// lock them out. It runs when the verified_at column is first added.
+func backfillVerifiedAt(db *gorm.DB) error {
+	return db.Unscoped().Model(&models.User{}).
+		Where("verified_at IS NULL").
+		Update("verified_at", gorm.Expr("created_at")).Error
+}
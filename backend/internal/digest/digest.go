@@ -0,0 +1,206 @@
+package digest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"project-exchange/internal/config"
+	"project-exchange/internal/mailer"
+	"project-exchange/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// maxMatchingProjects caps the project suggestions in one digest.
+const maxMatchingProjects = 10
+
+// ProjectMessages counts unread chat messages in one project.
+type ProjectMessages struct {
+	ProjectID uint
+	Title     string
+	Count     int64
+}
+
+// Application is a new application to a project the user reviews.
+type Application struct {
+	ProjectID uint
+	Title     string
+	Applicant string
+}
+
+// Digest is what happened for a user between Since and Until.
+type Digest struct {
+	User         models.User
+	Since, Until time.Time
+	Messages     []ProjectMessages
+	Applications []Application
+	Projects     []models.Project // newly open projects needing the user's skills
+}
+
+// Empty reports whether there is nothing to tell the user.
+func (d Digest) Empty() bool {
+	return len(d.Messages) == 0 && len(d.Applications) == 0 && len(d.Projects) == 0
+}
+
+// Period is how far apart digests of the given frequency are sent.
+func Period(frequency string) time.Duration {
+	if frequency == models.DigestWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// Build collects what happened for the user in (since, until]: their unread
+// message notifications and the outbox events that matter to them. Muted
+// projects and notification types are left out, as they are in the app.
+func Build(db *gorm.DB, user models.User, since, until time.Time) (Digest, error) {
+	d := Digest{User: user, Since: since, Until: until}
+
+	var mutes []models.NotificationMute
+	if err := db.Where("user_id = ? AND project_id IS NULL", user.ID).Find(&mutes).Error; err != nil {
+		return d, err
+	}
+	mutedTypes := make(map[string]bool, len(mutes))
+	for _, m := range mutes {
+		mutedTypes[m.Type] = true
+	}
+	mutedProjects := db.Model(&models.NotificationMute{}).Select("project_id").
+		Where("user_id = ? AND project_id IS NOT NULL", user.ID)
+
+	window := db.Model(&models.OutboxEvent{}).
+		Where("outbox_events.created_at > ? AND outbox_events.created_at <= ?", since, until).
+		Where("outbox_events.actor_id <> ?", user.ID).
+		Where("outbox_events.project_id NOT IN (?)", mutedProjects)
+
+	// Messages the user hasn't read yet, counted from their notifications
+	if !mutedTypes[models.NotificationMessagePosted] {
+		err := db.Model(&models.Notification{}).
+			Select("projects.id AS project_id, projects.title AS title, COUNT(*) AS count").
+			Joins("JOIN projects ON projects.id = notifications.project_id AND projects.deleted_at IS NULL").
+			Joins("JOIN messages ON messages.id = notifications.subject_id AND messages.removed_at IS NULL").
+			Where("notifications.user_id = ? AND notifications.type = ? AND notifications.read_at IS NULL",
+				user.ID, models.NotificationMessagePosted).
+			Where("notifications.created_at > ? AND notifications.created_at <= ?", since, until).
+			Where("notifications.project_id NOT IN (?)", mutedProjects).
+			Group("projects.id, projects.title").Order("projects.id").
+			Scan(&d.Messages).Error
+		if err != nil {
+			return d, err
+		}
+	}
+
+	// Applications to projects the user owns or whose role lets them review
+	if !mutedTypes[models.NotificationApplicationReceived] {
+		reviewing := db.Model(&models.ProjectMember{}).Select("project_id").
+			Where("user_id = ? AND status = ?", user.ID, models.MemberStatusAccepted).
+			Where("role IN ?", models.MemberRolesWith(models.PermReviewApplications))
+		err := window.Session(&gorm.Session{}).
+			Select("projects.id AS project_id, projects.title AS title, users.name AS applicant").
+			Joins("JOIN projects ON projects.id = outbox_events.project_id AND projects.deleted_at IS NULL").
+			Joins("JOIN users ON users.id = outbox_events.actor_id").
+			Where("outbox_events.type = ?", models.OutboxApplicationSubmitted).
+			Where("projects.owner_id = ? OR projects.id IN (?)", user.ID, reviewing).
+			Order("outbox_events.id").
+			Scan(&d.Applications).Error
+		if err != nil {
+			return d, err
+		}
+	}
+
+	var err error
+	d.Projects, err = matchingProjects(db, window.Session(&gorm.Session{}), user)
+	return d, err
+}
+
+// matchingProjects returns projects published in the window that are still
+// open, that the user has no part in yet and that have a free position asking
+// for one of the user's skills.
+func matchingProjects(db, window *gorm.DB, user models.User) ([]models.Project, error) {
+	var userSkills []models.UserSkill
+	if err := db.Preload("Skill").Where("user_id = ?", user.ID).Find(&userSkills).Error; err != nil {
+		return nil, err
+	}
+	if len(userSkills) == 0 {
+		return nil, nil
+	}
+	has := make(map[string]bool, len(userSkills))
+	for _, us := range userSkills {
+		has[strings.ToLower(us.Skill.Name)] = true
+	}
+
+	published := window.Select("project_id").Where("outbox_events.type = ?", models.OutboxProjectPublished)
+	joined := db.Model(&models.ProjectMember{}).Select("project_id").Where("user_id = ?", user.ID)
+	var candidates []models.Project
+	err := db.Preload("Positions").
+		Where("id IN (?) AND status = ? AND owner_id <> ?", published, models.ProjectStatusOpen, user.ID).
+		Where("id NOT IN (?)", joined).
+		Order("id").Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []models.Project
+	for _, p := range candidates {
+		if projectNeeds(p, has) {
+			matches = append(matches, p)
+			if len(matches) == maxMatchingProjects {
+				break
+			}
+		}
+	}
+	return matches, nil
+}
+
+func projectNeeds(p models.Project, has map[string]bool) bool {
+	for _, pos := range p.Positions {
+		if pos.Filled {
+			continue
+		}
+		for _, skill := range pos.Skills {
+			if has[strings.ToLower(skill)] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Message renders the digest as a plain-text email.
+func (d Digest) Message() mailer.Message {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Hi %s,\n\nHere is what happened since %s.\n", d.User.Name, d.Since.Format("2 Jan 2006 15:04"))
+
+	if len(d.Messages) > 0 {
+		b.WriteString("\nUnread messages:\n")
+		for _, m := range d.Messages {
+			fmt.Fprintf(&b, "- %s: %d unread message(s) %s\n", m.Title, m.Count, projectURL(m.ProjectID))
+		}
+	}
+
+	if len(d.Applications) > 0 {
+		b.WriteString("\nNew applications:\n")
+		for _, a := range d.Applications {
+			fmt.Fprintf(&b, "- %s applied to %s %s\n", a.Applicant, a.Title, projectURL(a.ProjectID))
+		}
+	}
+
+	if len(d.Projects) > 0 {
+		b.WriteString("\nNew projects looking for your skills:\n")
+		for _, p := range d.Projects {
+			fmt.Fprintf(&b, "- %s %s\n", p.Title, projectURL(p.ID))
+		}
+	}
+
+	b.WriteString("\nYou can change how often you get this email in your profile settings.\n")
+
+	subject := "Your daily digest"
+	if d.User.DigestFrequency == models.DigestWeekly {
+		subject = "Your weekly digest"
+	}
+	return mailer.Message{To: d.User.Email, Subject: subject, Body: b.String()}
+}
+
+func projectURL(id uint) string {
+	return fmt.Sprintf("%s/projects/%d", strings.TrimRight(config.String("APP_URL", "http://localhost:3000"), "/"), id)
+}
@@ -0,0 +1,318 @@
+package digest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"project-exchange/internal/mailer"
+	"project-exchange/internal/models"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var now = time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// One connection, or each one would get its own in-memory database
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	err = db.AutoMigrate(
+		&models.User{},
+		&models.Project{},
+		&models.ProjectMember{},
+		&models.ProjectPosition{},
+		&models.Message{},
+		&models.Skill{},
+		&models.UserSkill{},
+		&models.Notification{},
+		&models.NotificationMute{},
+		&models.OutboxEvent{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func create(t *testing.T, db *gorm.DB, value interface{}) {
+	t.Helper()
+	if err := db.Create(value).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newUser(t *testing.T, db *gorm.DB, name, frequency string) models.User {
+	t.Helper()
+	user := models.User{
+		Name:            name,
+		Email:           strings.ToLower(name) + "@example.com",
+		PasswordHash:    "x",
+		DigestFrequency: frequency,
+	}
+	create(t, db, &user)
+	return user
+}
+
+// team is a project with an owner, one accepted member of each role and an
+// application submitted an hour ago.
+type team struct {
+	project                              models.Project
+	owner, maintainer, member, applicant models.User
+}
+
+func newTeam(t *testing.T, db *gorm.DB) team {
+	t.Helper()
+	tm := team{
+		owner:      newUser(t, db, "Owner", models.DigestDaily),
+		maintainer: newUser(t, db, "Maintainer", models.DigestDaily),
+		member:     newUser(t, db, "Member", models.DigestDaily),
+		applicant:  newUser(t, db, "Applicant", models.DigestOff),
+	}
+	tm.project = models.Project{OwnerID: tm.owner.ID, Title: "Compiler", Status: models.ProjectStatusOpen}
+	create(t, db, &tm.project)
+
+	for role, user := range map[string]models.User{models.RoleMaintainer: tm.maintainer, models.RoleMember: tm.member} {
+		create(t, db, &models.ProjectMember{
+			ProjectID: tm.project.ID, UserID: user.ID, Status: models.MemberStatusAccepted, Role: role,
+		})
+	}
+
+	application := models.ProjectMember{ProjectID: tm.project.ID, UserID: tm.applicant.ID, Status: models.MemberStatusPending}
+	create(t, db, &application)
+	create(t, db, &models.OutboxEvent{
+		Type:      models.OutboxApplicationSubmitted,
+		ProjectID: tm.project.ID,
+		ActorID:   tm.applicant.ID,
+		SubjectID: application.ID,
+		CreatedAt: now.Add(-time.Hour),
+	})
+	return tm
+}
+
+// postMessage adds a message by author with an unread notification for each
+// recipient.
+func postMessage(t *testing.T, db *gorm.DB, project models.Project, author models.User, recipients ...models.User) {
+	t.Helper()
+	message := models.Message{ProjectID: project.ID, UserID: author.ID, Content: "hello", CreatedAt: now.Add(-time.Hour)}
+	create(t, db, &message)
+	for _, r := range recipients {
+		create(t, db, &models.Notification{
+			UserID:    r.ID,
+			Type:      models.NotificationMessagePosted,
+			ProjectID: &project.ID,
+			ActorID:   &author.ID,
+			SubjectID: message.ID,
+			CreatedAt: now.Add(-time.Hour),
+		})
+	}
+}
+
+func TestBuildShowsApplicationsToReviewers(t *testing.T) {
+	db := newTestDB(t)
+	tm := newTeam(t, db)
+	since := now.Add(-24 * time.Hour)
+
+	for _, reviewer := range []models.User{tm.owner, tm.maintainer} {
+		d, err := Build(db, reviewer, since, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(d.Applications) != 1 || d.Applications[0].Applicant != "Applicant" {
+			t.Errorf("%s: applications = %+v, want the one from Applicant", reviewer.Name, d.Applications)
+		}
+	}
+
+	d, err := Build(db, tm.member, since, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Applications) != 0 {
+		t.Errorf("member: applications = %+v, want none", d.Applications)
+	}
+}
+
+func TestBuildCountsUnreadMessages(t *testing.T) {
+	db := newTestDB(t)
+	tm := newTeam(t, db)
+	postMessage(t, db, tm.project, tm.owner, tm.maintainer, tm.member)
+	postMessage(t, db, tm.project, tm.owner, tm.maintainer, tm.member)
+
+	// The member has read one of them
+	var read models.Notification
+	if err := db.Where("user_id = ?", tm.member.ID).First(&read).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&read).Update("read_at", now).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	for user, want := range map[*models.User]int64{&tm.maintainer: 2, &tm.member: 1} {
+		d, err := Build(db, *user, now.Add(-24*time.Hour), now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(d.Messages) != 1 || d.Messages[0].Count != want {
+			t.Errorf("%s: messages = %+v, want %d unread", user.Name, d.Messages, want)
+		}
+	}
+}
+
+func TestBuildLeavesOutMutes(t *testing.T) {
+	db := newTestDB(t)
+	tm := newTeam(t, db)
+	postMessage(t, db, tm.project, tm.member, tm.maintainer)
+
+	create(t, db, &models.NotificationMute{UserID: tm.maintainer.ID, Type: models.NotificationMessagePosted})
+	d, err := Build(db, tm.maintainer, now.Add(-24*time.Hour), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Messages) != 0 || len(d.Applications) != 1 {
+		t.Errorf("muted type: messages = %+v, applications = %+v", d.Messages, d.Applications)
+	}
+
+	create(t, db, &models.NotificationMute{UserID: tm.maintainer.ID, ProjectID: &tm.project.ID})
+	d, err = Build(db, tm.maintainer, now.Add(-24*time.Hour), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Empty() {
+		t.Errorf("muted project: digest = %+v, want empty", d)
+	}
+}
+
+func TestBuildSuggestsMatchingProjects(t *testing.T) {
+	db := newTestDB(t)
+	tm := newTeam(t, db)
+
+	skill := models.Skill{Name: "Go", Slug: "go"}
+	create(t, db, &skill)
+	create(t, db, &models.UserSkill{UserID: tm.applicant.ID, SkillID: skill.ID})
+	create(t, db, &models.ProjectPosition{ProjectID: tm.project.ID, Role: "Backend", Skills: models.StringList{"go"}, Seats: 1})
+	create(t, db, &models.OutboxEvent{
+		Type:      models.OutboxProjectPublished,
+		ProjectID: tm.project.ID,
+		ActorID:   tm.owner.ID,
+		SubjectID: tm.project.ID,
+		CreatedAt: now.Add(-time.Hour),
+	})
+
+	// The applicant already applied, so only an outsider with the skill hears of it
+	outsider := newUser(t, db, "Outsider", models.DigestDaily)
+	create(t, db, &models.UserSkill{UserID: outsider.ID, SkillID: skill.ID})
+
+	for user, want := range map[*models.User]int{&tm.applicant: 0, &outsider: 1} {
+		d, err := Build(db, *user, now.Add(-24*time.Hour), now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(d.Projects) != want {
+			t.Errorf("%s: projects = %+v, want %d", user.Name, d.Projects, want)
+		}
+	}
+}
+
+type fakeMailer struct {
+	sent []mailer.Message
+	err  error
+}
+
+func (m *fakeMailer) Send(msg mailer.Message) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func lastDigestAt(t *testing.T, db *gorm.DB, user models.User) *time.Time {
+	t.Helper()
+	if err := db.First(&user, user.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user.LastDigestAt
+}
+
+func TestSendDueMailsOncePerPeriod(t *testing.T) {
+	db := newTestDB(t)
+	tm := newTeam(t, db)
+	system := newUser(t, db, "System", models.DigestDaily)
+	if err := db.Model(&system).Update("is_system", true).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	m := &fakeMailer{}
+	sent, err := SendDue(db, m, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Owner and maintainer have an application to review; the member has
+	// nothing new and the applicant has digests off
+	if sent != 2 || len(m.sent) != 2 {
+		t.Fatalf("sent = %d, mails = %d, want 2", sent, len(m.sent))
+	}
+	for i, to := range []string{tm.owner.Email, tm.maintainer.Email} {
+		if m.sent[i].To != to || m.sent[i].Subject != "Your daily digest" {
+			t.Errorf("mail %d = %q to %s, want the daily digest to %s", i, m.sent[i].Subject, m.sent[i].To, to)
+		}
+	}
+
+	// Everyone due starts a new period, even without a mail
+	for _, user := range []models.User{tm.owner, tm.maintainer, tm.member} {
+		if at := lastDigestAt(t, db, user); at == nil || !at.Equal(now) {
+			t.Errorf("%s: last digest at %v, want %v", user.Name, at, now)
+		}
+	}
+	for _, user := range []models.User{tm.applicant, system} {
+		if at := lastDigestAt(t, db, user); at != nil {
+			t.Errorf("%s: last digest at %v, want none", user.Name, at)
+		}
+	}
+
+	sent, err = SendDue(db, m, now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 0 {
+		t.Errorf("second run within the period sent %d digests", sent)
+	}
+}
+
+func TestSendDueRetriesFailedSends(t *testing.T) {
+	db := newTestDB(t)
+	tm := newTeam(t, db)
+
+	m := &fakeMailer{err: errors.New("smtp down")}
+	sent, err := SendDue(db, m, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 0 {
+		t.Fatalf("sent = %d while the mailer fails", sent)
+	}
+	if at := lastDigestAt(t, db, tm.owner); at != nil {
+		t.Fatalf("owner: last digest at %v after a failed send", at)
+	}
+
+	m.err = nil
+	sent, err = SendDue(db, m, now.Add(15*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 2 {
+		t.Errorf("retry sent %d digests, want 2", sent)
+	}
+}
@@ -0,0 +1,64 @@
+package digest
+
+import (
+	"log"
+	"time"
+
+	"project-exchange/internal/mailer"
+	"project-exchange/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// SendDue mails a digest to every user whose digest period has passed since
+// the last one and returns how many were sent. Users with nothing new are
+// skipped but their period starts over. A failed send is retried on the
+// next run.
+func SendDue(db *gorm.DB, m mailer.Mailer, now time.Time) (int, error) {
+	sent := 0
+	for _, frequency := range []string{models.DigestDaily, models.DigestWeekly} {
+		period := Period(frequency)
+
+		var users []models.User
+		err := db.Where("digest_frequency = ? AND is_system = ?", frequency, false).
+			Where("last_digest_at IS NULL OR last_digest_at <= ?", now.Add(-period)).
+			Find(&users).Error
+		if err != nil {
+			return sent, err
+		}
+
+		for _, user := range users {
+			// Digests never reach back further than one period
+			since := now.Add(-period)
+			if user.LastDigestAt != nil && user.LastDigestAt.After(since) {
+				since = *user.LastDigestAt
+			}
+
+			d, err := Build(db, user, since, now)
+			if err != nil {
+				return sent, err
+			}
+
+			if !d.Empty() {
+				if err := m.Send(d.Message()); err != nil {
+					log.Printf("Failed to send digest to user %d: %v", user.ID, err)
+					continue
+				}
+				sent++
+			}
+
+			if err := db.Model(&user).Update("last_digest_at", now).Error; err != nil {
+				return sent, err
+			}
+		}
+	}
+
+	return sent, nil
+}
+
+// Prune deletes outbox events older than the longest digest period, which no
+// digest will read again.
+func Prune(db *gorm.DB, now time.Time) error {
+	cutoff := now.Add(-Period(models.DigestWeekly))
+	return db.Where("created_at < ?", cutoff).Delete(&models.OutboxEvent{}).Error
+}
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"project-exchange/internal/database"
+	"project-exchange/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// @Summary Export my data
+// @Description Download a zip archive with your profile, owned projects, memberships and authored messages as JSON files
+// @Tags users
+// @Security BearerAuth
+// @Produce application/zip
+// @Success 200 {file} file
+// @Failure 404 {object} map[string]interface{}
+// @Router /me/export [get]
+func ExportMyData(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	db := database.GetDB()
+
+	var user models.User
+	if err := db.Preload("SkillLevels.Skill").First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var projects []models.Project
+	var memberships []models.ProjectMember
+	var messages []models.Message
+
+	err := db.Preload("Positions").Preload("Questions", orderQuestions).
+		Where("owner_id = ?", userID).Order("id").Find(&projects).Error
+	if err == nil {
+		err = db.Preload("Project").Preload("Position").Preload("History").Preload("Answers").
+			Where("user_id = ?", userID).Order("id").Find(&memberships).Error
+	}
+	if err == nil {
+		err = db.Where("user_id = ?", userID).Order("id").Find(&messages).Error
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export data"})
+		return
+	}
+
+	// Applicants keep their own cover letters
+	applications := make([]Application, len(memberships))
+	for i, m := range memberships {
+		applications[i] = newApplication(m)
+	}
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", user.ToResponse()},
+		{"projects.json", projects},
+		{"memberships.json", applications},
+		{"messages.json", messages},
+	}
+
+	filename := fmt.Sprintf("project-exchange-%d-%s.zip", userID, time.Now().Format("20060102"))
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	// The response is already under way, so errors past this point can only be logged
+	archive := zip.NewWriter(c.Writer)
+	for _, file := range files {
+		w, err := archive.Create(file.name)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(file.data); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+	if err := archive.Close(); err != nil {
+		c.Error(err)
+	}
+}
+
+// @Summary Delete my account
+// @Description Delete your account after confirming your password. Each owned project goes to its longest-standing maintainer, or member if there is none, and is archived when nobody is left to take it over. Your messages and history entries are kept under a "Deleted user" placeholder
+// @Tags users
+// @Security BearerAuth
+// @Accept json
+// @Param confirmation body DeleteAccountRequest true "Current password"
+// @Success 204
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /me [delete]
+func DeleteMyAccount(c *gin.Context) {
+	var req DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := loadCurrentUserWithPassword(c, req.Password)
+	if !ok {
+		return
+	}
+
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		var projects []models.Project
+		if err := tx.Where("owner_id = ?", user.ID).Find(&projects).Error; err != nil {
+			return err
+		}
+		for _, project := range projects {
+			if err := handOverProject(tx, project, user.ID); err != nil {
+				return err
+			}
+		}
+
+		if err := leaveAllProjects(tx, user.ID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		err := tx.Model(&models.Invitation{}).
+			Where("invitee_id = ? AND status = ?", user.ID, models.InvitationStatusPending).
+			Updates(map[string]interface{}{"status": models.InvitationStatusDeclined, "responded_at": now}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&models.OwnershipTransfer{}).
+			Where("to_user_id = ? AND status = ?", user.ID, models.TransferStatusPending).
+			Updates(map[string]interface{}{"status": models.TransferStatusDeclined, "responded_at": now}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := database.ReassignToDeletedUser(tx, user.ID); err != nil {
+			return err
+		}
+
+		if err := revokeUserTokens(tx, user.ID); err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.UserSkill{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.MessageReaction{}).Error; err != nil {
+			return err
+		}
+
+		// Drop personal details now and free the email address for a new
+		// account; the row itself is purged after the retention period
+		err = tx.Model(&user).Updates(map[string]interface{}{
+			"name":      "Deleted user",
+			"email":     fmt.Sprintf("deleted-%d@%s", user.ID, models.ReservedEmailDomain),
+			"skills":    "",
+			"bio":       "",
+			"available": false,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Delete(&user).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// handOverProject makes the longest-standing maintainer, or failing that
+// member, the new owner of a project whose owner is leaving. Projects nobody
+// can take over are archived.
+func handOverProject(tx *gorm.DB, project models.Project, ownerID uint) error {
+	if err := closePendingTransfer(tx, project.ID, models.TransferStatusCancelled); err != nil {
+		return err
+	}
+
+	var successor models.ProjectMember
+	err := tx.Where("project_id = ? AND status = ? AND role IN ?", project.ID, models.MemberStatusAccepted,
+		[]string{models.RoleMaintainer, models.RoleMember}).
+		Order(gorm.Expr("CASE role WHEN ? THEN 0 ELSE 1 END, id", models.RoleMaintainer)).
+		First(&successor).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	if err == nil {
+		if err := tx.Model(&project).Update("owner_id", successor.UserID).Error; err != nil {
+			return err
+		}
+		// Ownership supersedes the successor's membership
+		if err := setMemberStatus(tx, &successor, models.MemberStatusLeft, ownerID); err != nil {
+			return err
+		}
+		return recordProjectEvent(tx, project.ID, ownerID, models.ProjectEventOwnershipTransferred,
+			formatUserID(ownerID), formatUserID(successor.UserID))
+	}
+
+	// Nobody to take over: the project is archived under the placeholder
+	// account so it stays readable
+	placeholder, err := database.DeletedUser(tx)
+	if err != nil {
+		return err
+	}
+	from := project.Status
+	err = tx.Model(&project).Updates(map[string]interface{}{
+		"owner_id": placeholder.ID,
+		"status":   models.ProjectStatusArchived,
+	}).Error
+	if err != nil || from == models.ProjectStatusArchived {
+		return err
+	}
+	return recordProjectEvent(tx, project.ID, ownerID, models.ProjectEventStatusChanged,
+		from, models.ProjectStatusArchived)
+}
+
+// leaveAllProjects withdraws the user's pending applications and ends their
+// accepted memberships, freeing the seats they held.
+func leaveAllProjects(tx *gorm.DB, userID uint) error {
+	var members []models.ProjectMember
+	err := tx.Where("user_id = ? AND status IN ?", userID,
+		[]string{models.MemberStatusPending, models.MemberStatusAccepted}).
+		Find(&members).Error
+	if err != nil {
+		return err
+	}
+
+	for i := range members {
+		status := models.MemberStatusLeft
+		if members[i].Status == models.MemberStatusPending {
+			status = models.MemberStatusWithdrawn
+		}
+		if err := setMemberStatus(tx, &members[i], status, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"net/http"
+
+	"project-exchange/internal/database"
+	"project-exchange/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var memberStatuses = map[string]bool{
+	models.MemberStatusPending:   true,
+	models.MemberStatusAccepted:  true,
+	models.MemberStatusRejected:  true,
+	models.MemberStatusWithdrawn: true,
+	models.MemberStatusLeft:      true,
+	models.MemberStatusRemoved:   true,
+}
+
+func memberKey(m models.ProjectMember) (float64, uint) { return 0, m.ID }
+
+// Application is a membership as its reviewers and the applicant see it,
+// with the cover letter that is left out everywhere else.
+type Application struct {
+	models.ProjectMember
+	CoverLetter string `json:"cover_letter,omitempty"`
+}
+
+func newApplication(m models.ProjectMember) Application {
+	return Application{ProjectMember: m, CoverLetter: m.CoverLetter}
+}
+
+// applicationPage turns a page of memberships into a page of applications.
+func applicationPage(page Page[models.ProjectMember]) Page[Application] {
+	items := make([]Application, len(page.Items))
+	for i, m := range page.Items {
+		items[i] = newApplication(m)
+	}
+	return Page[Application]{Items: items, NextCursor: page.NextCursor, Total: page.Total}
+}
+
+// @Summary List project applications
+// @Description List applications to a project (owner or maintainer)
+// @Tags members
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Project ID"
+// @Param status query string false "Filter by status (pending/accepted/rejected/withdrawn/left/removed)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param sort query string false "Sort order (newest/oldest)"
+// @Param cursor query string false "Cursor from a previous page"
+// @Param total query bool false "Include the total count"
+// @Success 200 {object} Page[Application]
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /projects/{id}/applications [get]
+func GetProjectApplications(c *gin.Context) {
+	project, ok := authorizeProject(c, models.PermReviewApplications, "view applications")
+	if !ok {
+		return
+	}
+
+	params, err := parsePageParams(c, creationSorts, "newest")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := database.GetDB().Model(&models.ProjectMember{}).Where("project_id = ?", project.ID)
+	if status := c.Query("status"); status != "" {
+		if !memberStatuses[status] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+			return
+		}
+		query = query.Where("status = ?", status)
+	}
+
+	total, err := params.count(query.Session(&gorm.Session{}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch applications"})
+		return
+	}
+
+	var members []models.ProjectMember
+	if err := params.apply(query, "id").Preload("User").Preload("Position").Preload("Answers").Find(&members).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch applications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, applicationPage(newPage(members, params, total, memberKey)))
+}
+
+// @Summary List my applications
+// @Description List the authenticated user's applications and memberships across projects
+// @Tags members
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Filter by status (pending/accepted/rejected/withdrawn/left/removed)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param sort query string false "Sort order (newest/oldest)"
+// @Param cursor query string false "Cursor from a previous page"
+// @Param total query bool false "Include the total count"
+// @Success 200 {object} Page[Application]
+// @Failure 400 {object} map[string]interface{}
+// @Router /me/applications [get]
+func GetMyApplications(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	params, err := parsePageParams(c, creationSorts, "newest")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Applications to deleted projects are hidden along with the project
+	query := database.GetDB().Model(&models.ProjectMember{}).
+		Joins("JOIN projects ON projects.id = project_members.project_id AND projects.deleted_at IS NULL").
+		Where("project_members.user_id = ?", userID)
+	if status := c.Query("status"); status != "" {
+		if !memberStatuses[status] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+			return
+		}
+		query = query.Where("project_members.status = ?", status)
+	}
+
+	total, err := params.count(query.Session(&gorm.Session{}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch applications"})
+		return
+	}
+
+	var members []models.ProjectMember
+	if err := params.apply(query, "project_members.id").Preload("Project.Owner").Preload("Position").Preload("Answers").Find(&members).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch applications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, applicationPage(newPage(members, params, total, memberKey)))
+}
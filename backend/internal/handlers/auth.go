@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"errors"
+	"log"
 	"net/http"
 	"os"
 	"time"
@@ -11,6 +13,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 type RegisterRequest struct {
@@ -24,13 +27,24 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type TokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"` // access token lifetime in seconds
+}
+
 type AuthResponse struct {
-	Token string                `json:"token"`
-	User  models.UserResponse `json:"user"`
+	TokenResponse
+	User models.UserResponse `json:"user"`
 }
 
 type Claims struct {
-	UserID uint `json:"user_id"`
+	UserID    uint   `json:"user_id"`
+	SessionID string `json:"sid"`
 	jwt.RegisteredClaims
 }
 
@@ -51,6 +65,11 @@ func Register(c *gin.Context) {
 		return
 	}
 
+	if models.IsReservedEmail(req.Email) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This email address is reserved"})
+		return
+	}
+
 	// Check if user already exists
 	var existingUser models.User
 	if err := database.GetDB().Where("email = ?", req.Email).First(&existingUser).Error; err == nil {
@@ -70,6 +89,7 @@ func Register(c *gin.Context) {
 		Name:         req.Name,
 		Email:        req.Email,
 		PasswordHash: string(hashedPassword),
+		Available:    true,
 	}
 
 	if err := database.GetDB().Create(&user).Error; err != nil {
@@ -77,25 +97,23 @@ func Register(c *gin.Context) {
 		return
 	}
 
-	// Generate JWT token
-	token, err := generateToken(user.ID)
+	// Send verification email; the account is usable either way
+	if err := sendVerificationEmail(user); err != nil {
+		log.Println("Failed to send verification email:", err)
+	}
+
+	// Generate JWT tokens
+	tokens, err := issueTokens(user.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
-	userResponse := models.UserResponse{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		Skills:    user.Skills,
-		Bio:       user.Bio,
-		CreatedAt: user.CreatedAt,
-	}
+	userResponse := user.ToResponse()
 
 	c.JSON(http.StatusCreated, AuthResponse{
-		Token: token,
-		User:  userResponse,
+		TokenResponse: tokens,
+		User:          userResponse,
 	})
 }
 
@@ -129,33 +147,88 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	// Generate JWT token
-	token, err := generateToken(user.ID)
+	// Generate JWT tokens
+	tokens, err := issueTokens(user.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
-	userResponse := models.UserResponse{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		Skills:    user.Skills,
-		Bio:       user.Bio,
-		CreatedAt: user.CreatedAt,
-	}
+	userResponse := user.ToResponse()
 
 	c.JSON(http.StatusOK, AuthResponse{
-		Token: token,
-		User:  userResponse,
+		TokenResponse: tokens,
+		User:          userResponse,
 	})
 }
 
-func generateToken(userID uint) (string, error) {
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new access token and refresh token. Reusing an old refresh token revokes the whole session.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param token body RefreshRequest true "Refresh token"
+// @Success 200 {object} TokenResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /auth/refresh [post]
+func Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := rotateRefreshToken(req.RefreshToken)
+	if errors.Is(err, errRefreshTokenReused) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token has already been used, session revoked"})
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// @Summary Logout
+// @Description Revoke the session the refresh token belongs to. Access tokens of that session stop working immediately.
+// @Tags auth
+// @Accept json
+// @Param token body RefreshRequest true "Refresh token"
+// @Success 204
+// @Failure 400 {object} map[string]interface{}
+// @Router /auth/logout [post]
+func Logout(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Unknown tokens are ignored so logout is idempotent
+	var record models.RefreshToken
+	if err := database.GetDB().Where("token_hash = ?", hashToken(req.RefreshToken)).First(&record).Error; err == nil {
+		if err := revokeTokenFamily(database.GetDB(), record.FamilyID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to logout"})
+			return
+		}
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func generateToken(userID uint, sessionID string) (string, error) {
 	claims := Claims{
-		UserID: userID,
+		UserID:    userID,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(accessTokenTTL())),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
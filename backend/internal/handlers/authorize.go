@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"project-exchange/internal/database"
+	"project-exchange/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// projectRole returns the user's role in the project: owner, the role of their
+// accepted membership, or "" if they are not on the team.
+func projectRole(project models.Project, userID uint) string {
+	if userID == 0 {
+		return ""
+	}
+	if project.OwnerID == userID {
+		return models.RoleOwner
+	}
+
+	var member models.ProjectMember
+	err := database.GetDB().
+		Where("project_id = ? AND user_id = ? AND status = ?", project.ID, userID, models.MemberStatusAccepted).
+		First(&member).Error
+	if err != nil {
+		return ""
+	}
+	return member.Role
+}
+
+// can reports whether the user's role in the project grants the permission.
+func can(project models.Project, userID uint, perm models.Permission) bool {
+	return models.RoleCan(projectRole(project, userID), perm)
+}
+
+// authorizeProject loads the project from the :id parameter and checks that
+// the authenticated user's role grants perm, writing an error response if
+// not. action completes the "You are not allowed to ..." error message.
+func authorizeProject(c *gin.Context, perm models.Permission, action string) (models.Project, bool) {
+	var project models.Project
+
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return project, false
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return project, false
+	}
+
+	if err := database.GetDB().First(&project, projectID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return project, false
+	}
+
+	if !can(project, userID.(uint), perm) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to " + action})
+		return project, false
+	}
+
+	return project, true
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+
+	"project-exchange/internal/database"
+	"project-exchange/internal/models"
+	"project-exchange/internal/skills"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultCandidates = 10
+	maxCandidates     = 50
+
+	// Upper bound on users scored per request
+	candidatePool = 500
+
+	candidateWeightSkills = 0.75
+	candidateWeightLevel  = 0.25
+)
+
+// Candidate is a user suggested for a project, with the position they fit
+// best and why they were picked.
+type Candidate struct {
+	User          models.PublicUserResponse `json:"user"`
+	Score         float64                   `json:"score"`
+	MatchedSkills []string                  `json:"matched_skills"`
+	PositionID    *uint                     `json:"position_id,omitempty"`
+	Reasons       []string                  `json:"reasons"`
+}
+
+// @Summary Suggest candidates for a project
+// @Description Suggest available users whose skills match the project's open positions (owner or maintainer). Current members and applicants are left out; email addresses are not included
+// @Tags projects
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Project ID"
+// @Param limit query int false "Number of candidates (default 10, max 50)"
+// @Success 200 {object} Page[Candidate]
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /projects/{id}/candidates [get]
+func GetProjectCandidates(c *gin.Context) {
+	project, ok := authorizeProject(c, models.PermReviewApplications, "view candidates")
+	if !ok {
+		return
+	}
+
+	limit, err := parseLimit(c, defaultCandidates, maxCandidates)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := database.GetDB()
+
+	positions, err := loadPositions(db, project.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch positions"})
+		return
+	}
+
+	// Only positions with free seats describe what the project still needs
+	var open []models.ProjectPosition
+	var skillIDs []uint
+	for _, position := range positions {
+		if position.Filled || position.Seats <= int(derefInt64(position.SeatsTaken)) {
+			continue
+		}
+		open = append(open, position)
+		for _, name := range position.Skills {
+			if skill, err := skills.Lookup(db, name); err == nil {
+				skillIDs = append(skillIDs, skill.ID)
+			}
+		}
+	}
+
+	candidates := []Candidate{}
+	if len(skillIDs) == 0 {
+		c.JSON(http.StatusOK, Page[Candidate]{Items: candidates})
+		return
+	}
+
+	var users []models.User
+	err = db.Preload("SkillLevels.Skill").
+		Where("available = ? AND id <> ?", true, project.OwnerID).
+		Where("id IN (?)", db.Model(&models.UserSkill{}).Select("user_id").Where("skill_id IN ?", skillIDs)).
+		Where("id NOT IN (?)", db.Model(&models.ProjectMember{}).Select("user_id").Where("project_id = ?", project.ID)).
+		Order("id desc").
+		Limit(candidatePool).
+		Find(&users).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch candidates"})
+		return
+	}
+
+	for _, user := range users {
+		candidates = append(candidates, scoreCandidate(user, project, open))
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	c.JSON(http.StatusOK, Page[Candidate]{Items: candidates})
+}
+
+// scoreCandidate rates a user against the project's open positions by skill
+// overlap with the best fitting position and by level fit.
+func scoreCandidate(user models.User, project models.Project, open []models.ProjectPosition) Candidate {
+	candidate := Candidate{User: user.ToPublicResponse(), MatchedSkills: []string{}}
+	userSkills := userSkillIndex(user.SkillLevels)
+
+	var best models.ProjectPosition
+	var bestMatched, bestLevels []string
+	bestScore := -1.0
+	for _, position := range open {
+		var matched, levels []string
+		for _, name := range position.Skills {
+			if userSkill, ok := userSkills[skills.Normalize(name)]; ok {
+				matched = append(matched, name)
+				levels = append(levels, userSkill.Level)
+			}
+		}
+		if len(position.Skills) == 0 {
+			continue
+		}
+
+		score := float64(len(matched)) / float64(len(position.Skills))
+		if score > bestScore {
+			best, bestMatched, bestLevels, bestScore = position, matched, levels, score
+		}
+	}
+
+	wantLevel := project.Level
+	if best.Level != "" {
+		wantLevel = best.Level
+	}
+	haveLevel := averageLevel(bestLevels)
+	levelScore := levelFit(haveLevel, wantLevel)
+
+	if len(bestMatched) > 0 {
+		candidate.MatchedSkills = bestMatched
+		candidate.PositionID = &best.ID
+		candidate.Reasons = append(candidate.Reasons, fmt.Sprintf("Has %s needed for %s (%d of %d skills)",
+			strings.Join(bestMatched, ", "), best.Role, len(bestMatched), len(best.Skills)))
+	}
+	switch {
+	case haveLevel == "" || wantLevel == "":
+	case levelScore == 1:
+		candidate.Reasons = append(candidate.Reasons, fmt.Sprintf("Level %s, as wanted", haveLevel))
+	case levelScore > 0:
+		candidate.Reasons = append(candidate.Reasons, fmt.Sprintf("Level %s, close to the wanted %s", haveLevel, wantLevel))
+	}
+
+	score := candidateWeightSkills*math.Max(bestScore, 0) + candidateWeightLevel*levelScore
+	candidate.Score = math.Round(score*1000) / 1000
+
+	return candidate
+}
@@ -0,0 +1,403 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"project-exchange/internal/database"
+	"project-exchange/internal/models"
+	"project-exchange/internal/realtime"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	maxEmojiLength    = 32
+	maxPinnedMessages = 25
+)
+
+// MessagePage is a page of chat messages along with the project's pinned
+// messages.
+type MessagePage struct {
+	Page[models.Message]
+	Pinned []models.Message `json:"pinned"`
+}
+
+// ThreadPage is a page of replies along with the message that started the
+// thread.
+type ThreadPage struct {
+	Page[models.Message]
+	Root models.Message `json:"root"`
+}
+
+type ReactionRequest struct {
+	Emoji string `json:"emoji" binding:"required"`
+}
+
+// @Summary Get message thread
+// @Description Get the message that started a thread and a page of its replies, oldest first. Any message of the thread can be given
+// @Tags messages
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Project ID"
+// @Param msgId path int true "Message ID"
+// @Param sort query string false "oldest (default) or newest"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param total query bool false "Include the total count"
+// @Success 200 {object} ThreadPage
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /projects/{id}/messages/{msgId}/thread [get]
+func GetMessageThread(c *gin.Context) {
+	project, ok := authorizeProject(c, models.PermViewProject, "view messages")
+	if !ok {
+		return
+	}
+
+	root, ok := loadMessage(c, project)
+	if !ok {
+		return
+	}
+
+	params, err := parsePageParams(c, creationSorts, "oldest")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := database.GetDB()
+	if root.ReplyToID != nil {
+		var parent models.Message
+		if err := db.Preload("User").First(&parent, *root.ReplyToID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Message not found"})
+			return
+		}
+		root = parent
+	}
+
+	query := db.Model(&models.Message{}).Where("reply_to_id = ?", root.ID)
+
+	total, err := params.count(query.Session(&gorm.Session{}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch thread"})
+		return
+	}
+
+	var replies []models.Message
+	if err := params.apply(query, "id").Preload("User").Find(&replies).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch thread"})
+		return
+	}
+
+	roots := []models.Message{root}
+	if err := decorateMessages(db, roots); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch thread"})
+		return
+	}
+	if err := decorateMessages(db, replies); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch thread"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ThreadPage{
+		Page: newPage(replies, params, total, messageKey),
+		Root: roots[0],
+	})
+}
+
+// @Summary React to message
+// @Description Add your emoji reaction to a message. Reacting twice with the same emoji has no effect. Returns the message's reaction counts
+// @Tags messages
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Project ID"
+// @Param msgId path int true "Message ID"
+// @Param reaction body ReactionRequest true "Emoji"
+// @Success 200 {array} models.ReactionCount
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /projects/{id}/messages/{msgId}/reactions [post]
+func AddReaction(c *gin.Context) {
+	project, ok := authorizeProject(c, models.PermPostMessages, "react to messages")
+	if !ok {
+		return
+	}
+
+	message, ok := loadMessage(c, project)
+	if !ok {
+		return
+	}
+
+	var req ReactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	emoji, err := validateEmoji(req.Emoji)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !chatWritable(c, project, message) {
+		return
+	}
+
+	reaction := models.MessageReaction{MessageID: message.ID, UserID: c.GetUint("user_id"), Emoji: emoji}
+	if err := database.GetDB().Where(reaction).FirstOrCreate(&reaction).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add reaction"})
+		return
+	}
+
+	respondWithReactions(c, message)
+}
+
+// @Summary Remove reaction
+// @Description Remove your emoji reaction from a message. Returns the message's reaction counts
+// @Tags messages
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Project ID"
+// @Param msgId path int true "Message ID"
+// @Param emoji path string true "Emoji"
+// @Success 200 {array} models.ReactionCount
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /projects/{id}/messages/{msgId}/reactions/{emoji} [delete]
+func RemoveReaction(c *gin.Context) {
+	project, ok := authorizeProject(c, models.PermPostMessages, "react to messages")
+	if !ok {
+		return
+	}
+
+	message, ok := loadMessage(c, project)
+	if !ok {
+		return
+	}
+
+	if !chatWritable(c, project, message) {
+		return
+	}
+
+	result := database.GetDB().
+		Where("message_id = ? AND user_id = ? AND emoji = ?", message.ID, c.GetUint("user_id"), c.Param("emoji")).
+		Delete(&models.MessageReaction{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove reaction"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reaction not found"})
+		return
+	}
+
+	respondWithReactions(c, message)
+}
+
+// @Summary Pin message
+// @Description Pin a message to the top of the project chat (only owner). A project can have at most 25 pinned messages
+// @Tags messages
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Project ID"
+// @Param msgId path int true "Message ID"
+// @Success 200 {object} models.Message
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /projects/{id}/messages/{msgId}/pin [post]
+func PinMessage(c *gin.Context) {
+	setMessagePinned(c, true)
+}
+
+// @Summary Unpin message
+// @Description Take a message off the project's pinned list (only owner)
+// @Tags messages
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Project ID"
+// @Param msgId path int true "Message ID"
+// @Success 200 {object} models.Message
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /projects/{id}/messages/{msgId}/pin [delete]
+func UnpinMessage(c *gin.Context) {
+	setMessagePinned(c, false)
+}
+
+func setMessagePinned(c *gin.Context, pinned bool) {
+	project, ok := authorizeProject(c, models.PermPinMessages, "pin messages")
+	if !ok {
+		return
+	}
+
+	message, ok := loadMessage(c, project)
+	if !ok {
+		return
+	}
+
+	if !chatWritable(c, project, message) {
+		return
+	}
+
+	// Conditional updates keep concurrent pins from overwriting each other or
+	// going over the limit
+	db := database.GetDB()
+	query := db.Model(&message)
+	updates := map[string]interface{}{"pinned_at": nil, "pinned_by_id": nil}
+	if pinned {
+		pinnedCount := db.Model(&models.Message{}).Select("COUNT(*)").
+			Where("project_id = ? AND pinned_at IS NOT NULL", project.ID)
+		query = query.Where("pinned_at IS NULL AND (?) < ?", pinnedCount, maxPinnedMessages)
+		updates = map[string]interface{}{"pinned_at": time.Now(), "pinned_by_id": c.GetUint("user_id")}
+	} else {
+		query = query.Where("pinned_at IS NOT NULL")
+	}
+
+	result := query.Updates(updates)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update message"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		if pinned {
+			// Updates has already set PinnedAt on message
+			var current models.Message
+			if err := db.Select("pinned_at").First(&current, message.ID).Error; err == nil && current.PinnedAt == nil {
+				c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("At most %d messages can be pinned", maxPinnedMessages)})
+				return
+			}
+			c.JSON(http.StatusConflict, gin.H{"error": "Message is already pinned"})
+		} else {
+			c.JSON(http.StatusConflict, gin.H{"error": "Message is not pinned"})
+		}
+		return
+	}
+
+	db.Preload("User").First(&message, message.ID)
+	messages := []models.Message{message}
+	if err := decorateMessages(db, messages); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch message"})
+		return
+	}
+
+	realtime.GetHub().Broadcast(project.ID, realtime.EventMessageUpdated, messages[0])
+
+	c.JSON(http.StatusOK, messages[0])
+}
+
+// chatWritable refuses changes to the chat of an archived project or to a
+// removed message, writing an error response.
+func chatWritable(c *gin.Context, project models.Project, message models.Message) bool {
+	if project.Status == models.ProjectStatusArchived {
+		c.JSON(http.StatusConflict, gin.H{"error": "Project is archived, its chat is read-only"})
+		return false
+	}
+	if message.RemovedAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Message has been removed"})
+		return false
+	}
+	return true
+}
+
+// respondWithReactions writes the message's reaction counts and pushes them to
+// the project chat.
+func respondWithReactions(c *gin.Context, message models.Message) {
+	messages := []models.Message{message}
+	if err := decorateMessages(database.GetDB(), messages); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reactions"})
+		return
+	}
+
+	reactions := messages[0].Reactions
+	if reactions == nil {
+		reactions = []models.ReactionCount{}
+	}
+
+	realtime.GetHub().Broadcast(message.ProjectID, realtime.EventReactionsUpdated, gin.H{
+		"message_id": message.ID,
+		"reactions":  reactions,
+	})
+
+	c.JSON(http.StatusOK, reactions)
+}
+
+// validateEmoji trims a reaction and checks that it is one short emoji.
+func validateEmoji(raw string) (string, error) {
+	emoji := strings.TrimSpace(raw)
+	if emoji == "" || len(emoji) > maxEmojiLength {
+		return "", fmt.Errorf("Emoji must be between 1 and %d bytes", maxEmojiLength)
+	}
+	if !isEmoji(emoji) {
+		return "", errors.New("Reaction must be a single emoji")
+	}
+	return emoji, nil
+}
+
+// decorateMessages fills in the reply counts and reaction counts of messages
+// loaded for the chat.
+func decorateMessages(db *gorm.DB, messages []models.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(messages))
+	for i, m := range messages {
+		ids[i] = m.ID
+	}
+
+	var counts []struct {
+		ReplyToID uint
+		Count     int64
+	}
+	err := db.Model(&models.Message{}).Select("reply_to_id, COUNT(*) AS count").
+		Where("reply_to_id IN ?", ids).Group("reply_to_id").Scan(&counts).Error
+	if err != nil {
+		return err
+	}
+	replies := make(map[uint]int64, len(counts))
+	for _, rc := range counts {
+		replies[rc.ReplyToID] = rc.Count
+	}
+
+	var reactions []models.MessageReaction
+	if err := db.Where("message_id IN ?", ids).Order("id").Find(&reactions).Error; err != nil {
+		return err
+	}
+
+	// Emojis are listed in the order they were first used on each message
+	byMessage := make(map[uint][]models.ReactionCount)
+	for _, r := range reactions {
+		list := byMessage[r.MessageID]
+		i := slices.IndexFunc(list, func(rc models.ReactionCount) bool { return rc.Emoji == r.Emoji })
+		if i < 0 {
+			list = append(list, models.ReactionCount{Emoji: r.Emoji})
+			i = len(list) - 1
+		}
+		list[i].Count++
+		list[i].UserIDs = append(list[i].UserIDs, r.UserID)
+		byMessage[r.MessageID] = list
+	}
+
+	for i := range messages {
+		messages[i].ReplyCount = replies[messages[i].ID]
+		messages[i].Reactions = byMessage[messages[i].ID]
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"project-exchange/internal/database"
+	"project-exchange/internal/mailer"
+	"project-exchange/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const purposeChangeEmail = "change_email"
+
+var errEmailTaken = errors.New("User with this email already exists")
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+type ChangeEmailRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+// @Summary Change password
+// @Description Change your password. Every session is signed out and a fresh pair of tokens is returned for the current client
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} TokenResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /me/password [post]
+func ChangePassword(c *gin.Context) {
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := loadCurrentUserWithPassword(c, req.CurrentPassword)
+	if !ok {
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	var tokens TokenResponse
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Model(&user).Updates(map[string]interface{}{
+			"password_hash":          string(hashedPassword),
+			"credentials_changed_at": now,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		// Outstanding reset links would undo the change
+		err = tx.Model(&models.PasswordReset{}).
+			Where("user_id = ? AND used_at IS NULL", user.ID).
+			Update("used_at", now).Error
+		if err != nil {
+			return err
+		}
+
+		if err := revokeUserTokens(tx, user.ID); err != nil {
+			return err
+		}
+
+		familyID, err := newRandomToken()
+		if err != nil {
+			return err
+		}
+		tokens, err = issueTokensInFamily(tx, user.ID, familyID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change password"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// @Summary Change email
+// @Description Start changing your email address. A confirmation link is sent to the new address; the current address stays in use until it is confirmed
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body ChangeEmailRequest true "New email and current password"
+// @Success 202 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /me/email [post]
+func ChangeEmail(c *gin.Context) {
+	var req ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := loadCurrentUserWithPassword(c, req.Password)
+	if !ok {
+		return
+	}
+
+	email := strings.TrimSpace(req.Email)
+	if strings.EqualFold(email, user.Email) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This is already your email address"})
+		return
+	}
+	if models.IsReservedEmail(email) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This email address is reserved"})
+		return
+	}
+	if emailTaken(database.GetDB(), email) {
+		c.JSON(http.StatusConflict, gin.H{"error": errEmailTaken.Error()})
+		return
+	}
+
+	if err := database.GetDB().Model(&user).Update("pending_email", email).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change email"})
+		return
+	}
+
+	token, err := signEmailToken(user.ID, email, purposeChangeEmail, emailVerificationTTL())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	err = mailer.GetMailer().Send(mailer.Message{
+		To:      email,
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf("Hi %s,\n\nOpen the link below to start using this address for your account. It expires in %s.\n\n%s\n\nIf you did not request this, you can ignore this email.\n",
+			user.Name, emailVerificationTTL(), appURL("/confirm-email?token="+token)),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send confirmation email"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Confirmation email sent to the new address"})
+}
+
+// @Summary Confirm email change
+// @Description Switch the account to the new email address using the token from the confirmation email. The new address counts as verified and every session is signed out
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body VerifyEmailRequest true "Confirmation token"
+// @Success 200 {object} models.UserResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /auth/email/confirm [post]
+func ConfirmEmailChange(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	claims, err := parseEmailToken(req.Token, purposeChangeEmail)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired confirmation token"})
+		return
+	}
+
+	var user models.User
+	oldEmail := ""
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		// Only the most recently requested address can be confirmed
+		if err := tx.First(&user, claims.UserID).Error; err != nil || user.PendingEmail != claims.Email {
+			return gorm.ErrRecordNotFound
+		}
+		if emailTaken(tx, claims.Email) {
+			return errEmailTaken
+		}
+
+		oldEmail = user.Email
+		now := time.Now()
+		err := tx.Model(&user).Updates(map[string]interface{}{
+			"email":                  claims.Email,
+			"pending_email":          "",
+			"verified_at":            now,
+			"credentials_changed_at": now,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return revokeUserTokens(tx, user.ID)
+	})
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired confirmation token"})
+		return
+	case errors.Is(err, errEmailTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change email"})
+		return
+	}
+
+	// Let the old address know in case the change wasn't wanted
+	err = mailer.GetMailer().Send(mailer.Message{
+		To:      oldEmail,
+		Subject: "Your email address was changed",
+		Body: fmt.Sprintf("Hi %s,\n\nThe email address of your account was changed to %s.\n\nIf you did not do this, reset your password and contact support.\n",
+			user.Name, user.Email),
+	})
+	if err != nil {
+		log.Println("Failed to send email change notice:", err)
+	}
+
+	c.JSON(http.StatusOK, user.ToResponse())
+}
+
+// loadCurrentUserWithPassword loads the authenticated user and checks their
+// current password, writing an error response if either fails.
+func loadCurrentUserWithPassword(c *gin.Context, password string) (models.User, bool) {
+	var user models.User
+	if err := database.GetDB().First(&user, c.GetUint("user_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return user, false
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
+		return user, false
+	}
+
+	return user, true
+}
+
+// emailTaken reports whether an account, including a deleted one that has not
+// been purged yet, already uses the address.
+func emailTaken(db *gorm.DB, email string) bool {
+	var count int64
+	db.Unscoped().Model(&models.User{}).Where("email = ?", email).Count(&count)
+	return count > 0
+}
@@ -0,0 +1,494 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"project-exchange/internal/database"
+	"project-exchange/internal/models"
+	"project-exchange/internal/notifications"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const maxInvitationMessageSize = 2000
+
+var (
+	errAlreadyMember        = errors.New("You are already a member of this project")
+	errCannotJoin           = errors.New("You can no longer join this project")
+	errInvitationNotPending = errors.New("Invitation is no longer pending")
+	errPositionNotInProject = errors.New("Position does not belong to this project")
+)
+
+var invitationStatuses = map[string]bool{
+	models.InvitationStatusPending:  true,
+	models.InvitationStatusAccepted: true,
+	models.InvitationStatusDeclined: true,
+	models.InvitationStatusRevoked:  true,
+}
+
+func invitationKey(i models.Invitation) (float64, uint) { return 0, i.ID }
+
+type InviteRequest struct {
+	UserID     uint   `json:"user_id" binding:"required"`
+	PositionID *uint  `json:"position_id"`
+	Message    string `json:"message"`
+}
+
+// @Summary Invite a user
+// @Description Invite a specific user to join the project (owner or maintainer)
+// @Tags invitations
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Project ID"
+// @Param invitation body InviteRequest true "Invitation"
+// @Success 201 {object} models.Invitation
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /projects/{id}/invitations [post]
+func CreateInvitation(c *gin.Context) {
+	project, ok := authorizeProject(c, models.PermInviteMembers, "invite members")
+	if !ok {
+		return
+	}
+
+	var req InviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(req.Message) > maxInvitationMessageSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Message is too long"})
+		return
+	}
+
+	if req.UserID == project.OwnerID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Project owner cannot be invited"})
+		return
+	}
+
+	if !models.ProjectAcceptsMembers(project.Status) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Project is not accepting members"})
+		return
+	}
+
+	db := database.GetDB()
+
+	var invitee models.User
+	if err := db.First(&invitee, req.UserID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if !writePositionError(c, checkInvitePosition(db, project.ID, req.PositionID)) {
+		return
+	}
+
+	var count int64
+	db.Model(&models.ProjectMember{}).
+		Where("project_id = ? AND user_id = ? AND status = ?", project.ID, invitee.ID, models.MemberStatusAccepted).
+		Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already a member of this project"})
+		return
+	}
+
+	db.Model(&models.Invitation{}).
+		Where("project_id = ? AND invitee_id = ? AND status = ?", project.ID, invitee.ID, models.InvitationStatusPending).
+		Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "User already has a pending invitation to this project"})
+		return
+	}
+
+	invitation := models.Invitation{
+		ProjectID:  project.ID,
+		InviterID:  c.GetUint("user_id"),
+		InviteeID:  invitee.ID,
+		PositionID: req.PositionID,
+		Message:    req.Message,
+		Status:     models.InvitationStatusPending,
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&invitation).Error; err != nil {
+			return err
+		}
+		return notifications.Notify(tx, models.Notification{
+			Type:      models.NotificationInvitationReceived,
+			ProjectID: &invitation.ProjectID,
+			ActorID:   &invitation.InviterID,
+			SubjectID: invitation.ID,
+		}, invitation.InviteeID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation"})
+		return
+	}
+
+	db.Preload("Invitee").Preload("Position").First(&invitation, invitation.ID)
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// @Summary List project invitations
+// @Description List invitations sent for a project (owner or maintainer)
+// @Tags invitations
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Project ID"
+// @Param status query string false "Filter by status (pending, accepted, declined, revoked)"
+// @Param sort query string false "newest (default) or oldest"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param total query bool false "Include the total count"
+// @Success 200 {object} Page[models.Invitation]
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /projects/{id}/invitations [get]
+func GetProjectInvitations(c *gin.Context) {
+	project, ok := authorizeProject(c, models.PermInviteMembers, "view invitations")
+	if !ok {
+		return
+	}
+
+	query := database.GetDB().Model(&models.Invitation{}).Where("project_id = ?", project.ID)
+	listInvitations(c, query, "invitations.id", "Invitee", "Position")
+}
+
+// @Summary Revoke an invitation
+// @Description Revoke a pending invitation (owner or maintainer)
+// @Tags invitations
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param invitationId path int true "Invitation ID"
+// @Success 204
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /projects/{id}/invitations/{invitationId} [delete]
+func RevokeInvitation(c *gin.Context) {
+	project, ok := authorizeProject(c, models.PermInviteMembers, "revoke invitations")
+	if !ok {
+		return
+	}
+
+	invitationID, err := strconv.ParseUint(c.Param("invitationId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invitation ID"})
+		return
+	}
+
+	var invitation models.Invitation
+	if err := database.GetDB().Where("id = ? AND project_id = ?", invitationID, project.ID).First(&invitation).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+		return
+	}
+
+	err = respondToInvitation(database.GetDB(), &invitation, models.InvitationStatusRevoked)
+	if errors.Is(err, errInvitationNotPending) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke invitation"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary List my invitations
+// @Description List invitations received by the current user
+// @Tags invitations
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Filter by status (pending, accepted, declined, revoked)"
+// @Param sort query string false "newest (default) or oldest"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param total query bool false "Include the total count"
+// @Success 200 {object} Page[models.Invitation]
+// @Failure 400 {object} map[string]interface{}
+// @Router /me/invitations [get]
+func GetMyInvitations(c *gin.Context) {
+	// Invitations to deleted projects are hidden along with the project
+	query := database.GetDB().Model(&models.Invitation{}).
+		Joins("JOIN projects ON projects.id = invitations.project_id AND projects.deleted_at IS NULL").
+		Where("invitations.invitee_id = ?", c.GetUint("user_id"))
+	listInvitations(c, query, "invitations.id", "Project.Owner", "Inviter", "Position")
+}
+
+// @Summary Accept an invitation
+// @Description Accept a pending invitation and join the project as an accepted member
+// @Tags invitations
+// @Security BearerAuth
+// @Produce json
+// @Param invitationId path int true "Invitation ID"
+// @Success 200 {object} models.ProjectMember
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /me/invitations/{invitationId}/accept [post]
+func AcceptInvitation(c *gin.Context) {
+	invitation, ok := loadMyInvitation(c)
+	if !ok {
+		return
+	}
+
+	var project models.Project
+	if err := database.GetDB().First(&project, invitation.ProjectID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+
+	if !models.ProjectAcceptsMembers(project.Status) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Project is not accepting members"})
+		return
+	}
+
+	var member models.ProjectMember
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := respondToInvitation(tx, &invitation, models.InvitationStatusAccepted); err != nil {
+			return err
+		}
+		var err error
+		member, err = admitMember(tx, project.ID, invitation.InviteeID, invitation.PositionID, true)
+		return err
+	})
+	if !writeAdmitError(c, err) {
+		return
+	}
+
+	database.GetDB().Preload("User").Preload("Position").First(&member, member.ID)
+
+	c.JSON(http.StatusOK, member)
+}
+
+// @Summary Decline an invitation
+// @Description Decline a pending invitation
+// @Tags invitations
+// @Security BearerAuth
+// @Param invitationId path int true "Invitation ID"
+// @Success 204
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /me/invitations/{invitationId}/decline [post]
+func DeclineInvitation(c *gin.Context) {
+	invitation, ok := loadMyInvitation(c)
+	if !ok {
+		return
+	}
+
+	err := respondToInvitation(database.GetDB(), &invitation, models.InvitationStatusDeclined)
+	if errors.Is(err, errInvitationNotPending) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decline invitation"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// listInvitations writes a page of the invitations matched by query.
+func listInvitations(c *gin.Context, query *gorm.DB, idColumn string, preloads ...string) {
+	params, err := parsePageParams(c, creationSorts, "newest")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if status := c.Query("status"); status != "" {
+		if !invitationStatuses[status] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+			return
+		}
+		query = query.Where("invitations.status = ?", status)
+	}
+
+	total, err := params.count(query.Session(&gorm.Session{}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invitations"})
+		return
+	}
+
+	query = params.apply(query, idColumn)
+	for _, preload := range preloads {
+		query = query.Preload(preload)
+	}
+
+	var invitations []models.Invitation
+	if err := query.Find(&invitations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invitations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newPage(invitations, params, total, invitationKey))
+}
+
+// loadMyInvitation loads the invitation from the :invitationId parameter
+// addressed to the authenticated user, writing an error response if there is
+// none.
+func loadMyInvitation(c *gin.Context) (models.Invitation, bool) {
+	var invitation models.Invitation
+
+	invitationID, err := strconv.ParseUint(c.Param("invitationId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invitation ID"})
+		return invitation, false
+	}
+
+	err = database.GetDB().Where("id = ? AND invitee_id = ?", invitationID, c.GetUint("user_id")).First(&invitation).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+		return invitation, false
+	}
+
+	return invitation, true
+}
+
+// respondToInvitation moves a pending invitation to its final status,
+// guarding against a concurrent response.
+func respondToInvitation(tx *gorm.DB, invitation *models.Invitation, status string) error {
+	now := time.Now()
+	res := tx.Model(&models.Invitation{}).
+		Where("id = ? AND status = ?", invitation.ID, models.InvitationStatusPending).
+		Updates(map[string]interface{}{"status": status, "responded_at": now})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errInvitationNotPending
+	}
+
+	invitation.Status = status
+	invitation.RespondedAt = &now
+	return nil
+}
+
+// admitMember makes the user an accepted member of the project, creating the
+// membership or moving an existing one to accepted. direct is true for
+// invitations the owner addressed to this user, which may also readmit
+// rejected and removed users. Other pending invitations of the user to the
+// project are settled as accepted.
+func admitMember(tx *gorm.DB, projectID, userID uint, positionID *uint, direct bool) (models.ProjectMember, error) {
+	var member models.ProjectMember
+	err := tx.Where("project_id = ? AND user_id = ?", projectID, userID).First(&member).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if positionID != nil {
+			if err := ensureSeatFree(tx, *positionID); err != nil {
+				return member, err
+			}
+		}
+
+		member = models.ProjectMember{
+			ProjectID:  projectID,
+			UserID:     userID,
+			Status:     models.MemberStatusAccepted,
+			PositionID: positionID,
+		}
+		if err := tx.Create(&member).Error; err != nil {
+			return member, err
+		}
+		if err := recordMemberChange(tx, member, "", userID); err != nil {
+			return member, err
+		}
+		if positionID != nil {
+			if err := refreshPositionFilled(tx, *positionID); err != nil {
+				return member, err
+			}
+		}
+
+	case err != nil:
+		return member, err
+
+	case member.Status == models.MemberStatusAccepted:
+		return member, errAlreadyMember
+
+	case !models.CanJoinByInvite(member.Status, direct):
+		return member, errCannotJoin
+
+	default:
+		// The invitation's position wins over the one applied for
+		if positionID != nil {
+			if err := tx.Model(&member).Update("position_id", *positionID).Error; err != nil {
+				return member, err
+			}
+			member.PositionID = positionID
+		}
+		if err := setMemberStatus(tx, &member, models.MemberStatusAccepted, userID); err != nil {
+			return member, err
+		}
+	}
+
+	err = tx.Model(&models.Invitation{}).
+		Where("project_id = ? AND invitee_id = ? AND status = ?", projectID, userID, models.InvitationStatusPending).
+		Updates(map[string]interface{}{"status": models.InvitationStatusAccepted, "responded_at": time.Now()}).Error
+	if err != nil {
+		return member, err
+	}
+
+	return member, enqueueMemberEvent(tx, member, models.WebhookEventMemberAccepted)
+}
+
+// writeAdmitError writes the response for a failed admitMember and reports
+// whether err was nil.
+func writeAdmitError(c *gin.Context, err error) bool {
+	var illegal *illegalTransitionError
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, errAlreadyMember), errors.Is(err, errInvitationNotPending),
+		errors.Is(err, errPositionFull), errors.Is(err, errPositionGone), errors.As(err, &illegal):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, errCannotJoin):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join project"})
+	}
+	return false
+}
+
+// checkInvitePosition checks that an optional position belongs to the
+// project and still has free seats.
+func checkInvitePosition(db *gorm.DB, projectID uint, positionID *uint) error {
+	if positionID == nil {
+		return nil
+	}
+
+	var position models.ProjectPosition
+	if err := db.Where("id = ? AND project_id = ?", *positionID, projectID).First(&position).Error; err != nil {
+		return errPositionNotInProject
+	}
+	if position.Filled {
+		return errPositionFull
+	}
+	return nil
+}
+
+// writePositionError writes the response for a failed checkInvitePosition
+// and reports whether err was nil.
+func writePositionError(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, errPositionFull):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+	return false
+}
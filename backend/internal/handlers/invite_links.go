@@ -0,0 +1,320 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"project-exchange/internal/database"
+	"project-exchange/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultInviteLinkHours = 7 * 24
+	maxInviteLinkHours     = 30 * 24
+	maxInviteLinkUses      = 1000
+)
+
+var errInviteLinkInvalid = errors.New("Invite link is no longer valid")
+
+func inviteLinkKey(l models.InviteLink) (float64, uint) { return 0, l.ID }
+
+type CreateInviteLinkRequest struct {
+	PositionID     *uint `json:"position_id"`
+	MaxUses        int   `json:"max_uses"`         // default 1
+	ExpiresInHours int   `json:"expires_in_hours"` // default 168 (7 days)
+}
+
+// InviteLinkResponse is returned once when a link is created; the token can't
+// be recovered later.
+type InviteLinkResponse struct {
+	models.InviteLink
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+// InvitePreview describes what joining through an invite link leads to.
+type InvitePreview struct {
+	Project   InviteProject           `json:"project"`
+	Position  *models.ProjectPosition `json:"position,omitempty"`
+	ExpiresAt time.Time               `json:"expires_at"`
+	UsesLeft  int                     `json:"uses_left"`
+}
+
+// InviteProject is the project as shown to anyone holding an invite link,
+// with the owner's public profile in place of the full user.
+type InviteProject struct {
+	models.Project
+	Owner models.PublicUserResponse `json:"owner"`
+}
+
+// @Summary Create an invite link
+// @Description Create a shareable link that adds whoever uses it as an accepted member (owner or maintainer). The token is only returned here
+// @Tags invitations
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Project ID"
+// @Param link body CreateInviteLinkRequest true "Link settings"
+// @Success 201 {object} InviteLinkResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /projects/{id}/invite-links [post]
+func CreateInviteLink(c *gin.Context) {
+	project, ok := authorizeProject(c, models.PermInviteMembers, "create invite links")
+	if !ok {
+		return
+	}
+
+	var req CreateInviteLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.MaxUses == 0 {
+		req.MaxUses = 1
+	}
+	if req.MaxUses < 1 || req.MaxUses > maxInviteLinkUses {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Max uses must be between 1 and %d", maxInviteLinkUses)})
+		return
+	}
+	if req.ExpiresInHours == 0 {
+		req.ExpiresInHours = defaultInviteLinkHours
+	}
+	if req.ExpiresInHours < 1 || req.ExpiresInHours > maxInviteLinkHours {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Expiry must be between 1 and %d hours", maxInviteLinkHours)})
+		return
+	}
+
+	if !models.ProjectAcceptsMembers(project.Status) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Project is not accepting members"})
+		return
+	}
+
+	if !writePositionError(c, checkInvitePosition(database.GetDB(), project.ID, req.PositionID)) {
+		return
+	}
+
+	token, err := newRandomToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invite link"})
+		return
+	}
+
+	link := models.InviteLink{
+		ProjectID:   project.ID,
+		CreatedByID: c.GetUint("user_id"),
+		TokenHash:   hashToken(token),
+		PositionID:  req.PositionID,
+		MaxUses:     req.MaxUses,
+		ExpiresAt:   time.Now().Add(time.Duration(req.ExpiresInHours) * time.Hour),
+	}
+	if err := database.GetDB().Create(&link).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invite link"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, InviteLinkResponse{
+		InviteLink: link,
+		Token:      token,
+		URL:        appURL("/invite/" + token),
+	})
+}
+
+// @Summary List invite links
+// @Description List the project's invite links, including expired and revoked ones (owner or maintainer)
+// @Tags invitations
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Project ID"
+// @Param sort query string false "newest (default) or oldest"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param total query bool false "Include the total count"
+// @Success 200 {object} Page[models.InviteLink]
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /projects/{id}/invite-links [get]
+func GetInviteLinks(c *gin.Context) {
+	project, ok := authorizeProject(c, models.PermInviteMembers, "view invite links")
+	if !ok {
+		return
+	}
+
+	params, err := parsePageParams(c, creationSorts, "newest")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := database.GetDB().Model(&models.InviteLink{}).Where("project_id = ?", project.ID)
+
+	total, err := params.count(query.Session(&gorm.Session{}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invite links"})
+		return
+	}
+
+	var links []models.InviteLink
+	if err := params.apply(query, "id").Preload("Position").Find(&links).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invite links"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newPage(links, params, total, inviteLinkKey))
+}
+
+// @Summary Revoke an invite link
+// @Description Revoke an invite link so it can no longer be used (owner or maintainer)
+// @Tags invitations
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param linkId path int true "Invite link ID"
+// @Success 204
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /projects/{id}/invite-links/{linkId} [delete]
+func RevokeInviteLink(c *gin.Context) {
+	project, ok := authorizeProject(c, models.PermInviteMembers, "revoke invite links")
+	if !ok {
+		return
+	}
+
+	linkID, err := strconv.ParseUint(c.Param("linkId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invite link ID"})
+		return
+	}
+
+	var link models.InviteLink
+	if err := database.GetDB().Where("id = ? AND project_id = ?", linkID, project.ID).First(&link).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invite link not found"})
+		return
+	}
+
+	// Revoking twice is harmless
+	err = database.GetDB().Model(&link).Where("revoked_at IS NULL").Update("revoked_at", time.Now()).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke invite link"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Preview an invite link
+// @Description Show the project an invite link leads to
+// @Tags invitations
+// @Produce json
+// @Param token path string true "Invite token"
+// @Success 200 {object} InvitePreview
+// @Failure 404 {object} map[string]interface{}
+// @Failure 410 {object} map[string]interface{}
+// @Router /invites/{token} [get]
+func GetInvite(c *gin.Context) {
+	link, project, ok := loadInviteLink(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, InvitePreview{
+		Project:   InviteProject{Project: project, Owner: project.Owner.ToPublicResponse()},
+		Position:  link.Position,
+		ExpiresAt: link.ExpiresAt,
+		UsesLeft:  link.MaxUses - link.Uses,
+	})
+}
+
+// @Summary Join by invite link
+// @Description Join the project as an accepted member using an invite link
+// @Tags invitations
+// @Security BearerAuth
+// @Produce json
+// @Param token path string true "Invite token"
+// @Success 200 {object} models.ProjectMember
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 410 {object} map[string]interface{}
+// @Router /invites/{token}/join [post]
+func JoinByInviteLink(c *gin.Context) {
+	link, project, ok := loadInviteLink(c)
+	if !ok {
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	if project.OwnerID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Project owner cannot join by invite"})
+		return
+	}
+
+	if !models.ProjectAcceptsMembers(project.Status) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Project is not accepting members"})
+		return
+	}
+
+	var member models.ProjectMember
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		// Take a use only while the link is still valid; a failed join gives it back
+		res := tx.Model(&models.InviteLink{}).
+			Where("id = ? AND uses < max_uses AND revoked_at IS NULL AND expires_at > ?", link.ID, time.Now()).
+			Update("uses", gorm.Expr("uses + 1"))
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errInviteLinkInvalid
+		}
+
+		var err error
+		member, err = admitMember(tx, project.ID, userID, link.PositionID, false)
+		return err
+	})
+	if errors.Is(err, errInviteLinkInvalid) {
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+		return
+	}
+	if !writeAdmitError(c, err) {
+		return
+	}
+
+	database.GetDB().Preload("User").Preload("Position").First(&member, member.ID)
+
+	c.JSON(http.StatusOK, member)
+}
+
+// loadInviteLink loads the usable invite link from the :token parameter and
+// its project, writing an error response if there is none.
+func loadInviteLink(c *gin.Context) (models.InviteLink, models.Project, bool) {
+	var link models.InviteLink
+	var project models.Project
+
+	if err := database.GetDB().Preload("Position").Where("token_hash = ?", hashToken(c.Param("token"))).First(&link).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invite link not found"})
+		return link, project, false
+	}
+
+	if err := database.GetDB().Scopes(withMemberCount).Preload("Owner").First(&project, link.ProjectID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return link, project, false
+	}
+
+	if !link.Usable(time.Now()) {
+		c.JSON(http.StatusGone, gin.H{"error": errInviteLinkInvalid.Error()})
+		return link, project, false
+	}
+
+	return link, project, true
+}
@@ -1,21 +1,30 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 
 	"project-exchange/internal/database"
 	"project-exchange/internal/models"
+	"project-exchange/internal/notifications"
+	"project-exchange/internal/realtime"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // @Summary Apply to project
-// @Description Apply to join a project
+// @Description Apply to join a project with an optional cover letter and answers to the project questionnaire. Users who withdrew or left earlier may apply again.
 // @Tags members
 // @Security BearerAuth
+// @Accept json
+// @Produce json
 // @Param id path int true "Project ID"
-// @Success 201 {object} models.ProjectMember
+// @Param application body ApplyRequest false "Cover letter and questionnaire answers"
+// @Success 201 {object} Application
 // @Failure 400 {object} map[string]interface{}
 // @Failure 409 {object} map[string]interface{}
 // @Router /projects/{id}/apply [post]
@@ -45,33 +54,119 @@ func ApplyToProject(c *gin.Context) {
 		return
 	}
 
-	// Check if user already applied
-	var existingMember models.ProjectMember
-	if err := database.GetDB().Where("project_id = ? AND user_id = ?", projectID, userID).First(&existingMember).Error; err == nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "You have already applied to this project"})
+	// Only open projects take applications
+	if project.Status != models.ProjectStatusOpen {
+		c.JSON(http.StatusConflict, gin.H{"error": "Project is not accepting applications"})
+		return
+	}
+
+	// The application body is optional for projects without a questionnaire
+	var req ApplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(req.CoverLetter) > maxCoverLetterSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cover letter is too long"})
+		return
+	}
+
+	// Projects with positions need applicants to pick one that still has seats
+	var positionCount int64
+	database.GetDB().Model(&models.ProjectPosition{}).Where("project_id = ?", project.ID).Count(&positionCount)
+	switch {
+	case positionCount > 0 && req.PositionID == nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Choose a position to apply to"})
+		return
+	case positionCount == 0 && req.PositionID != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Project has no positions"})
 		return
+	case req.PositionID != nil:
+		var position models.ProjectPosition
+		if err := database.GetDB().Where("id = ? AND project_id = ?", *req.PositionID, project.ID).First(&position).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Position does not belong to this project"})
+			return
+		}
+		if position.Filled {
+			c.JSON(http.StatusConflict, gin.H{"error": errPositionFull.Error()})
+			return
+		}
 	}
 
-	// Create application
-	member := models.ProjectMember{
-		ProjectID: uint(projectID),
-		UserID:    userID.(uint),
-		Status:    "pending",
+	var questions []models.ProjectQuestion
+	if err := database.GetDB().Where("project_id = ?", project.ID).Order("position asc").Find(&questions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load questionnaire"})
+		return
 	}
 
-	if err := database.GetDB().Create(&member).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to apply to project"})
+	answers, err := validateAnswers(questions, req.Answers)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	// Check if user already applied
+	var member models.ProjectMember
+	err = database.GetDB().Where("project_id = ? AND user_id = ?", projectID, userID).First(&member).Error
+	if err == nil {
+		// Withdrawn applicants and former members may apply again
+		switch {
+		case member.Status == models.MemberStatusPending || member.Status == models.MemberStatusAccepted:
+			c.JSON(http.StatusConflict, gin.H{"error": "You have already applied to this project"})
+			return
+		case !models.CanTransitionMember(member.Status, models.MemberStatusPending):
+			c.JSON(http.StatusConflict, gin.H{"error": "You can no longer apply to this project"})
+			return
+		}
+
+		if err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+			if err := transitionMember(tx, &member, models.MemberStatusPending, userID.(uint)); err != nil {
+				return err
+			}
+			if err := saveApplication(tx, &member, req, answers); err != nil {
+				return err
+			}
+			return notifyApplication(tx, project, member)
+		}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to apply to project"})
+			return
+		}
+	} else {
+		// Create application
+		member = models.ProjectMember{
+			ProjectID:   uint(projectID),
+			UserID:      userID.(uint),
+			Status:      models.MemberStatusPending,
+			PositionID:  req.PositionID,
+			CoverLetter: req.CoverLetter,
+		}
+
+		if err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&member).Error; err != nil {
+				return err
+			}
+			if err := recordMemberChange(tx, member, "", userID.(uint)); err != nil {
+				return err
+			}
+			if err := saveApplication(tx, &member, req, answers); err != nil {
+				return err
+			}
+			return notifyApplication(tx, project, member)
+		}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to apply to project"})
+			return
+		}
+	}
+
 	// Load user data
-	database.GetDB().Preload("User").First(&member, member.ID)
+	database.GetDB().Preload("User").Preload("Position").Preload("Answers").First(&member, member.ID)
 
-	c.JSON(http.StatusCreated, member)
+	c.JSON(http.StatusCreated, newApplication(member))
 }
 
 // @Summary Accept member
-// @Description Accept a member application (only project owner)
+// @Description Accept a pending member application (owner or maintainer)
 // @Tags members
 // @Security BearerAuth
 // @Param id path int true "Project ID"
@@ -80,54 +175,303 @@ func ApplyToProject(c *gin.Context) {
 // @Failure 400 {object} map[string]interface{}
 // @Failure 403 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
 // @Router /projects/{id}/accept/{userId} [post]
 func AcceptMember(c *gin.Context) {
-	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	changeMemberStatusAs(c, models.PermReviewApplications, models.MemberStatusAccepted, "accept members")
+}
+
+// @Summary Reject member
+// @Description Reject a pending member application (owner or maintainer)
+// @Tags members
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param userId path int true "User ID"
+// @Success 200 {object} models.ProjectMember
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /projects/{id}/reject/{userId} [post]
+func RejectMember(c *gin.Context) {
+	changeMemberStatusAs(c, models.PermReviewApplications, models.MemberStatusRejected, "reject members")
+}
+
+// @Summary Remove member
+// @Description Remove an accepted member from the project (owner or maintainer; only the owner can remove maintainers)
+// @Tags members
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param userId path int true "User ID"
+// @Success 200 {object} models.ProjectMember
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /projects/{id}/remove/{userId} [post]
+func RemoveMember(c *gin.Context) {
+	changeMemberStatusAs(c, models.PermRemoveMembers, models.MemberStatusRemoved, "remove members")
+}
+
+// @Summary Withdraw application
+// @Description Withdraw your own pending application
+// @Tags members
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Success 200 {object} models.ProjectMember
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /projects/{id}/withdraw [post]
+func WithdrawApplication(c *gin.Context) {
+	changeOwnMemberStatus(c, models.MemberStatusWithdrawn)
+}
+
+// @Summary Leave project
+// @Description Leave a project you are an accepted member of
+// @Tags members
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Success 200 {object} models.ProjectMember
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /projects/{id}/leave [post]
+func LeaveProject(c *gin.Context) {
+	changeOwnMemberStatus(c, models.MemberStatusLeft)
+}
+
+// saveApplication stores the position, cover letter and answers of a
+// (re-)application, replacing those of any earlier application.
+func saveApplication(tx *gorm.DB, member *models.ProjectMember, req ApplyRequest, answers []models.ApplicationAnswer) error {
+	member.PositionID = req.PositionID
+	member.CoverLetter = req.CoverLetter
+	if err := tx.Model(member).Select("position_id", "cover_letter").Updates(member).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("project_member_id = ?", member.ID).Delete(&models.ApplicationAnswer{}).Error; err != nil {
+		return err
+	}
+
+	for i := range answers {
+		answers[i].ProjectMemberID = member.ID
+		if err := tx.Create(&answers[i]).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// notifyApplication tells the project's reviewers, webhooks and digests about
+// a new application.
+func notifyApplication(tx *gorm.DB, project models.Project, member models.ProjectMember) error {
+	reviewers, err := teamWith(tx, project, models.PermReviewApplications)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return err
+	}
+
+	err = notifications.Notify(tx, models.Notification{
+		Type:      models.NotificationApplicationReceived,
+		ProjectID: &member.ProjectID,
+		ActorID:   &member.UserID,
+		SubjectID: member.ID,
+	}, reviewers...)
+	if err != nil {
+		return err
+	}
+
+	if err := recordOutbox(tx, models.OutboxApplicationSubmitted, member.ProjectID, member.UserID, member.ID); err != nil {
+		return err
+	}
+
+	return enqueueMemberEvent(tx, member, models.WebhookEventMemberApplied)
+}
+
+// notifyDecision tells an applicant that their application was accepted or
+// rejected, and webhooks about acceptances. Other transitions are made by the
+// members themselves.
+func notifyDecision(tx *gorm.DB, member models.ProjectMember, actorID uint) error {
+	var notificationType string
+	switch member.Status {
+	case models.MemberStatusAccepted:
+		notificationType = models.NotificationApplicationAccepted
+	case models.MemberStatusRejected:
+		notificationType = models.NotificationApplicationRejected
+	default:
+		return nil
+	}
+
+	err := notifications.Notify(tx, models.Notification{
+		Type:      notificationType,
+		ProjectID: &member.ProjectID,
+		ActorID:   &actorID,
+		SubjectID: member.ID,
+	}, member.UserID)
+	if err != nil || member.Status != models.MemberStatusAccepted {
+		return err
+	}
+
+	return enqueueMemberEvent(tx, member, models.WebhookEventMemberAccepted)
+}
+
+// changeMemberStatusAs moves another user's membership to the given status
+// on behalf of a team member whose role grants perm.
+func changeMemberStatusAs(c *gin.Context, perm models.Permission, status, action string) {
+	project, ok := authorizeProject(c, perm, action)
+	if !ok {
 		return
 	}
 
-	userIDToAccept, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	memberUserID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
 		return
 	}
 
-	ownerID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+	// Find the member application
+	var member models.ProjectMember
+	if err := database.GetDB().Where("project_id = ? AND user_id = ?", project.ID, memberUserID).First(&member).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member application not found"})
 		return
 	}
 
-	// Check if project exists and user is owner
-	var project models.Project
-	if err := database.GetDB().First(&project, projectID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+	// Maintainers manage the team but not each other
+	actorID := c.GetUint("user_id")
+	if member.Role == models.RoleMaintainer && member.Status == models.MemberStatusAccepted && project.OwnerID != actorID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only project owner can change the membership of a maintainer"})
 		return
 	}
 
-	if project.OwnerID != ownerID.(uint) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Only project owner can accept members"})
+	applyMemberTransition(c, &member, status, actorID)
+}
+
+// changeOwnMemberStatus moves the authenticated user's own membership to the
+// given status.
+func changeOwnMemberStatus(c *gin.Context, status string) {
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	// Find the member application
 	var member models.ProjectMember
-	if err := database.GetDB().Where("project_id = ? AND user_id = ?", projectID, userIDToAccept).First(&member).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Member application not found"})
+	if err := database.GetDB().Where("project_id = ? AND user_id = ?", projectID, userID).First(&member).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Membership not found"})
 		return
 	}
 
-	// Update status to accepted
-	member.Status = "accepted"
-	if err := database.GetDB().Save(&member).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept member"})
+	applyMemberTransition(c, &member, status, userID.(uint))
+}
+
+// applyMemberTransition runs the transition, writes the response and drops the
+// user's chat sockets if they are no longer a member.
+func applyMemberTransition(c *gin.Context, member *models.ProjectMember, status string, actorID uint) {
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := transitionMember(tx, member, status, actorID); err != nil {
+			return err
+		}
+		return notifyDecision(tx, *member, actorID)
+	})
+	var illegal *illegalTransitionError
+	if errors.As(err, &illegal) {
+		c.JSON(http.StatusConflict, gin.H{"error": illegal.Error()})
+		return
+	}
+	if errors.Is(err, errPositionFull) || errors.Is(err, errPositionGone) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update membership"})
 		return
 	}
 
+	if status == models.MemberStatusLeft || status == models.MemberStatusRemoved {
+		realtime.GetHub().DisconnectUser(member.ProjectID, member.UserID)
+	}
+
 	// Load user data
-	database.GetDB().Preload("User").First(&member, member.ID)
+	database.GetDB().Preload("User").Preload("Position").First(member, member.ID)
 
 	c.JSON(http.StatusOK, member)
 }
+
+type illegalTransitionError struct {
+	from, to string
+}
+
+func (e *illegalTransitionError) Error() string {
+	return fmt.Sprintf("Cannot change membership from %s to %s", e.from, e.to)
+}
+
+// transitionMember validates and applies a status change and records it in
+// the member's history.
+func transitionMember(tx *gorm.DB, member *models.ProjectMember, status string, actorID uint) error {
+	if !models.CanTransitionMember(member.Status, status) {
+		return &illegalTransitionError{from: member.Status, to: status}
+	}
+	return setMemberStatus(tx, member, status, actorID)
+}
+
+// setMemberStatus applies a status change without consulting the state
+// machine; callers are responsible for checking it is allowed.
+func setMemberStatus(tx *gorm.DB, member *models.ProjectMember, status string, actorID uint) error {
+	from := member.Status
+
+	// Accepting needs a free seat in the member's position
+	if status == models.MemberStatusAccepted && member.PositionID != nil {
+		if err := ensureSeatFree(tx, *member.PositionID); err != nil {
+			return err
+		}
+	}
+
+	// Former members lose their role; rejoining starts as a plain member
+	updates := map[string]interface{}{"status": status}
+	if from == models.MemberStatusAccepted {
+		updates["role"] = models.RoleMember
+	}
+
+	// Guard against a concurrent transition from the same state
+	res := tx.Model(&models.ProjectMember{}).
+		Where("id = ? AND status = ?", member.ID, from).
+		Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return &illegalTransitionError{from: from, to: status}
+	}
+
+	member.Status = status
+	if from == models.MemberStatusAccepted {
+		member.Role = models.RoleMember
+	}
+	if err := recordMemberChange(tx, *member, from, actorID); err != nil {
+		return err
+	}
+
+	// Keep the position's filled flag in step with its accepted members
+	if member.PositionID != nil && (from == models.MemberStatusAccepted || status == models.MemberStatusAccepted) {
+		return refreshPositionFilled(tx, *member.PositionID)
+	}
+	return nil
+}
+
+func recordMemberChange(tx *gorm.DB, member models.ProjectMember, from string, actorID uint) error {
+	return tx.Create(&models.MemberStatusChange{
+		ProjectMemberID: member.ID,
+		ProjectID:       member.ProjectID,
+		FromStatus:      from,
+		ToStatus:        member.Status,
+		ActorID:         actorID,
+	}).Error
+}
+
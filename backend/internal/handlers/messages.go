@@ -1,72 +1,107 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"slices"
 	"strconv"
+	"time"
 
+	"project-exchange/internal/config"
 	"project-exchange/internal/database"
 	"project-exchange/internal/models"
+	"project-exchange/internal/notifications"
+	"project-exchange/internal/realtime"
+	"project-exchange/internal/webhooks"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
+func messageKey(m models.Message) (float64, uint) { return 0, m.ID }
+
+var errMessageRemoved = errors.New("message removed")
+
 type CreateMessageRequest struct {
 	Content string `json:"content" binding:"required"`
+	// Message being replied to; replies always join the thread of the first message
+	ReplyToID *uint `json:"reply_to_id"`
+}
+
+type UpdateMessageRequest struct {
+	Content string `json:"content" binding:"required"`
 }
 
 // @Summary Get project messages
-// @Description Get all messages for a project
+// @Description Get a page of project messages. By default the first page holds the newest messages and next_cursor pages back in time; messages within a page are in chronological order. Replies are left out and counted in reply_count; pinned lists the pinned messages, most recently pinned first.
 // @Tags messages
 // @Security BearerAuth
 // @Param id path int true "Project ID"
-// @Success 200 {array} models.Message
+// @Param sort query string false "newest (default, pages backwards) or oldest (pages forwards)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from a previous page"
+// @Param total query bool false "Include the total count"
+// @Success 200 {object} MessagePage
+// @Failure 400 {object} map[string]interface{}
 // @Failure 403 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
 // @Router /projects/{id}/messages [get]
 func GetProjectMessages(c *gin.Context) {
-	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	project, ok := authorizeProject(c, models.PermViewProject, "view messages")
+	if !ok {
+		return
+	}
+
+	params, err := parsePageParams(c, creationSorts, "newest")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+	db := database.GetDB()
+	query := db.Model(&models.Message{}).Where("project_id = ? AND reply_to_id IS NULL", project.ID)
+
+	total, err := params.count(query.Session(&gorm.Session{}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch messages"})
 		return
 	}
 
-	// Check if project exists
-	var project models.Project
-	if err := database.GetDB().First(&project, projectID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+	var messages []models.Message
+	if err := params.apply(query, "id").Preload("User").Find(&messages).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch messages"})
 		return
 	}
 
-	// Check if user is owner or accepted member
-	if project.OwnerID != userID.(uint) {
-		var member models.ProjectMember
-		if err := database.GetDB().Where("project_id = ? AND user_id = ? AND status = ?", projectID, userID, "accepted").First(&member).Error; err != nil {
-			c.JSON(http.StatusForbidden, gin.H{"error": "You must be project owner or accepted member to view messages"})
-			return
-		}
+	pinned := []models.Message{}
+	err = db.Where("project_id = ? AND pinned_at IS NOT NULL", project.ID).
+		Order("pinned_at DESC").Limit(maxPinnedMessages).Preload("User").Find(&pinned).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch messages"})
+		return
 	}
 
-	var messages []models.Message
-	if err := database.GetDB().
-		Where("project_id = ?", projectID).
-		Preload("User").
-		Order("created_at asc").
-		Find(&messages).Error; err != nil {
+	if err := decorateMessages(db, messages); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch messages"})
 		return
 	}
+	if err := decorateMessages(db, pinned); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch messages"})
+		return
+	}
+
+	page := newPage(messages, params, total, messageKey)
+
+	// Pages walk backwards from the newest message, but each page reads top to bottom
+	if params.Sort.Desc {
+		slices.Reverse(page.Items)
+	}
 
-	c.JSON(http.StatusOK, messages)
+	c.JSON(http.StatusOK, MessagePage{Page: page, Pinned: pinned})
 }
 
 // @Summary Send message
-// @Description Send a message to project chat
+// @Description Send a message to project chat. Set reply_to_id to reply in the thread of another message
 // @Tags messages
 // @Security BearerAuth
 // @Accept json
@@ -79,15 +114,9 @@ func GetProjectMessages(c *gin.Context) {
 // @Failure 404 {object} map[string]interface{}
 // @Router /projects/{id}/messages [post]
 func SendMessage(c *gin.Context) {
-	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
-		return
-	}
-
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+	// Viewers can read the chat but not post
+	project, ok := authorizeProject(c, models.PermPostMessages, "send messages")
+	if !ok {
 		return
 	}
 
@@ -97,30 +126,56 @@ func SendMessage(c *gin.Context) {
 		return
 	}
 
-	// Check if project exists
-	var project models.Project
-	if err := database.GetDB().First(&project, projectID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+	// Archived projects keep their chat history read-only
+	if project.Status == models.ProjectStatusArchived {
+		c.JSON(http.StatusConflict, gin.H{"error": "Project is archived, its chat is read-only"})
 		return
 	}
 
-	// Check if user is owner or accepted member
-	if project.OwnerID != userID.(uint) {
-		var member models.ProjectMember
-		if err := database.GetDB().Where("project_id = ? AND user_id = ? AND status = ?", projectID, userID, "accepted").First(&member).Error; err != nil {
-			c.JSON(http.StatusForbidden, gin.H{"error": "You must be project owner or accepted member to send messages"})
-			return
-		}
-	}
-
 	// Create message
 	message := models.Message{
-		ProjectID: uint(projectID),
-		UserID:    userID.(uint),
+		ProjectID: project.ID,
+		UserID:    c.GetUint("user_id"),
 		Content:   req.Content,
 	}
 
-	if err := database.GetDB().Create(&message).Error; err != nil {
+	if req.ReplyToID != nil {
+		var parent models.Message
+		if err := database.GetDB().Where("id = ? AND project_id = ?", *req.ReplyToID, project.ID).First(&parent).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Message to reply to not found"})
+			return
+		}
+		// Threads are one level deep
+		message.ReplyToID = &parent.ID
+		if parent.ReplyToID != nil {
+			message.ReplyToID = parent.ReplyToID
+		}
+	}
+
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&message).Error; err != nil {
+			return err
+		}
+		team, err := teamWith(tx, project, models.PermViewProject)
+		if err != nil {
+			return err
+		}
+		err = notifications.Notify(tx, models.Notification{
+			Type:      models.NotificationMessagePosted,
+			ProjectID: &message.ProjectID,
+			ActorID:   &message.UserID,
+			SubjectID: message.ID,
+		}, team...)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Preload("User").First(&message, message.ID).Error; err != nil {
+			return err
+		}
+		return webhooks.Enqueue(tx, message.ProjectID, models.WebhookEventMessagePosted, webhooks.NewMessageData(message))
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send message"})
 		return
 	}
@@ -128,5 +183,244 @@ func SendMessage(c *gin.Context) {
 	// Load user data
 	database.GetDB().Preload("User").First(&message, message.ID)
 
+	// Push the message to everyone connected to the project chat
+	realtime.GetHub().Broadcast(message.ProjectID, realtime.EventMessageCreated, message)
+
 	c.JSON(http.StatusCreated, message)
 }
+
+// @Summary Edit message
+// @Description Change the text of your own message. Messages can only be edited for MESSAGE_EDIT_WINDOW after they were sent (15 minutes by default); the previous text is kept in the edit history
+// @Tags messages
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Project ID"
+// @Param msgId path int true "Message ID"
+// @Param message body UpdateMessageRequest true "New content"
+// @Success 200 {object} models.Message
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /projects/{id}/messages/{msgId} [patch]
+func UpdateMessage(c *gin.Context) {
+	project, ok := authorizeProject(c, models.PermPostMessages, "edit messages")
+	if !ok {
+		return
+	}
+
+	message, ok := loadMessage(c, project)
+	if !ok {
+		return
+	}
+
+	var req UpdateMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if message.UserID != c.GetUint("user_id") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only edit your own messages"})
+		return
+	}
+
+	if project.Status == models.ProjectStatusArchived {
+		c.JSON(http.StatusConflict, gin.H{"error": "Project is archived, its chat is read-only"})
+		return
+	}
+	if message.RemovedAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Message has been removed"})
+		return
+	}
+	if time.Since(message.CreatedAt) > messageEditWindow() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Message can no longer be edited"})
+		return
+	}
+
+	if req.Content == message.Content {
+		c.JSON(http.StatusOK, message)
+		return
+	}
+
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		edit := models.MessageEdit{MessageID: message.ID, Content: message.Content}
+		if err := tx.Create(&edit).Error; err != nil {
+			return err
+		}
+
+		// Guard against a concurrent removal
+		result := tx.Model(&message).Where("removed_at IS NULL").Updates(map[string]interface{}{
+			"content":   req.Content,
+			"edited_at": edit.CreatedAt,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errMessageRemoved
+		}
+		return nil
+	})
+	if errors.Is(err, errMessageRemoved) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Message has been removed"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to edit message"})
+		return
+	}
+
+	database.GetDB().Preload("User").First(&message, message.ID)
+	messages := []models.Message{message}
+	if err := decorateMessages(database.GetDB(), messages); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch message"})
+		return
+	}
+
+	realtime.GetHub().Broadcast(project.ID, realtime.EventMessageUpdated, messages[0])
+
+	c.JSON(http.StatusOK, messages[0])
+}
+
+// @Summary Get message edit history
+// @Description Get the earlier versions of a message, oldest first. Each entry holds the text the message had until the time in created_at
+// @Tags messages
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Project ID"
+// @Param msgId path int true "Message ID"
+// @Success 200 {array} models.MessageEdit
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /projects/{id}/messages/{msgId}/edits [get]
+func GetMessageEdits(c *gin.Context) {
+	project, ok := authorizeProject(c, models.PermViewProject, "view messages")
+	if !ok {
+		return
+	}
+
+	message, ok := loadMessage(c, project)
+	if !ok {
+		return
+	}
+
+	edits := []models.MessageEdit{}
+	if err := database.GetDB().Where("message_id = ?", message.ID).Order("id").Find(&edits).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch edit history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, edits)
+}
+
+// @Summary Delete message
+// @Description Remove a message from the project chat. Authors can remove their own messages; owners and maintainers can remove any. The message stays in the chat as a "message removed" stub; its text, edit history, reactions and notifications are discarded, it is unpinned and its text is redacted from stored webhook deliveries
+// @Tags messages
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param msgId path int true "Message ID"
+// @Success 204
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /projects/{id}/messages/{msgId} [delete]
+func DeleteMessage(c *gin.Context) {
+	project, ok := authorizeProject(c, models.PermViewProject, "delete messages")
+	if !ok {
+		return
+	}
+
+	message, ok := loadMessage(c, project)
+	if !ok {
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	if message.UserID != userID && !can(project, userID, models.PermModerateChat) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to delete other people's messages"})
+		return
+	}
+
+	if project.Status == models.ProjectStatusArchived {
+		c.JSON(http.StatusConflict, gin.H{"error": "Project is archived, its chat is read-only"})
+		return
+	}
+
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&message).Where("removed_at IS NULL").Updates(map[string]interface{}{
+			"content":       models.MessageRemovedContent,
+			"removed_at":    time.Now(),
+			"removed_by_id": userID,
+			"pinned_at":     nil,
+			"pinned_by_id":  nil,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errMessageRemoved
+		}
+
+		if err := tx.Where("message_id = ?", message.ID).Delete(&models.MessageReaction{}).Error; err != nil {
+			return err
+		}
+
+		// Earlier versions may hold whatever the message was removed for
+		if err := tx.Where("message_id = ?", message.ID).Delete(&models.MessageEdit{}).Error; err != nil {
+			return err
+		}
+
+		// Nothing is left to read
+		err := tx.Where("type = ? AND subject_id = ?", models.NotificationMessagePosted, message.ID).
+			Delete(&models.Notification{}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Preload("User").First(&message, message.ID).Error; err != nil {
+			return err
+		}
+		return webhooks.RedactMessage(tx, message)
+	})
+	if errors.Is(err, errMessageRemoved) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Message has already been removed"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete message"})
+		return
+	}
+
+	// Clients swap the message for the stub
+	realtime.GetHub().Broadcast(project.ID, realtime.EventMessageDeleted, message)
+
+	c.Status(http.StatusNoContent)
+}
+
+// loadMessage finds the message named in the URL within the project, writing
+// an error response if it fails.
+func loadMessage(c *gin.Context, project models.Project) (models.Message, bool) {
+	var message models.Message
+
+	messageID, err := strconv.ParseUint(c.Param("msgId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid message ID"})
+		return message, false
+	}
+
+	if err := database.GetDB().Preload("User").Where("id = ? AND project_id = ?", messageID, project.ID).First(&message).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Message not found"})
+		return message, false
+	}
+
+	return message, true
+}
+
+// messageEditWindow is how long after sending a message its author may edit it.
+func messageEditWindow() time.Duration {
+	return config.Duration("MESSAGE_EDIT_WINDOW", 15*time.Minute)
+}
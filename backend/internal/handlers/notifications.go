@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"project-exchange/internal/database"
+	"project-exchange/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func notificationKey(n models.Notification) (float64, uint) { return 0, n.ID }
+
+// NotificationPage is a page of notifications along with how many are unread
+// in total.
+type NotificationPage struct {
+	Page[models.Notification]
+	Unread int64 `json:"unread"`
+}
+
+// NotificationPreferences lists the notification types and projects a user
+// has muted.
+type NotificationPreferences struct {
+	MutedTypes      []string `json:"muted_types"`
+	MutedProjectIDs []uint   `json:"muted_project_ids"`
+}
+
+// @Summary List my notifications
+// @Description Get a page of your notifications, newest first, with the number of unread ones
+// @Tags notifications
+// @Security BearerAuth
+// @Produce json
+// @Param unread query bool false "Only unread notifications"
+// @Param sort query string false "newest (default) or oldest"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param total query bool false "Include the total count"
+// @Success 200 {object} NotificationPage
+// @Failure 400 {object} map[string]interface{}
+// @Router /me/notifications [get]
+func GetMyNotifications(c *gin.Context) {
+	params, err := parsePageParams(c, creationSorts, "newest")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := database.GetDB()
+	userID := c.GetUint("user_id")
+	query := db.Model(&models.Notification{}).Where("user_id = ?", userID)
+
+	if raw := c.Query("unread"); raw != "" {
+		unreadOnly, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid unread value"})
+			return
+		}
+		if unreadOnly {
+			query = query.Where("read_at IS NULL")
+		}
+	}
+
+	total, err := params.count(query.Session(&gorm.Session{}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+		return
+	}
+
+	var unread int64
+	if err := db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&unread).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+		return
+	}
+
+	var notifications []models.Notification
+	if err := params.apply(query, "id").Preload("Project").Preload("Actor").Find(&notifications).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, NotificationPage{
+		Page:   newPage(notifications, params, total, notificationKey),
+		Unread: unread,
+	})
+}
+
+// @Summary Mark notification read
+// @Description Mark one of your notifications as read
+// @Tags notifications
+// @Security BearerAuth
+// @Param notificationId path int true "Notification ID"
+// @Success 204
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /me/notifications/{notificationId}/read [post]
+func MarkNotificationRead(c *gin.Context) {
+	notificationID, err := strconv.ParseUint(c.Param("notificationId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	var notification models.Notification
+	if err := database.GetDB().Where("id = ? AND user_id = ?", notificationID, c.GetUint("user_id")).First(&notification).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+		return
+	}
+
+	// Marking twice keeps the first read time
+	err = database.GetDB().Model(&notification).Where("read_at IS NULL").Update("read_at", time.Now()).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Mark all notifications read
+// @Description Mark all of your unread notifications as read
+// @Tags notifications
+// @Security BearerAuth
+// @Success 204
+// @Router /me/notifications/read-all [post]
+func MarkAllNotificationsRead(c *gin.Context) {
+	err := database.GetDB().Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", c.GetUint("user_id")).
+		Update("read_at", time.Now()).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notifications"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Get notification preferences
+// @Description Get the notification types and projects you have muted
+// @Tags notifications
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} NotificationPreferences
+// @Router /me/notifications/preferences [get]
+func GetNotificationPreferences(c *gin.Context) {
+	prefs, err := loadNotificationPreferences(database.GetDB(), c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notification preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
+
+// @Summary Update notification preferences
+// @Description Replace the notification types and projects you have muted. Types: application_received, application_accepted, application_rejected, message_posted, invitation_received
+// @Tags notifications
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param preferences body NotificationPreferences true "Muted types and projects"
+// @Success 200 {object} NotificationPreferences
+// @Failure 400 {object} map[string]interface{}
+// @Router /me/notifications/preferences [put]
+func UpdateNotificationPreferences(c *gin.Context) {
+	var req NotificationPreferences
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var mutes []models.NotificationMute
+	userID := c.GetUint("user_id")
+	seen := make(map[string]bool)
+	for _, t := range req.MutedTypes {
+		if !models.IsNotificationType(t) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification type: " + t})
+			return
+		}
+		if !seen[t] {
+			seen[t] = true
+			mutes = append(mutes, models.NotificationMute{UserID: userID, Type: t})
+		}
+	}
+
+	seenProjects := make(map[uint]bool)
+	for _, id := range req.MutedProjectIDs {
+		if !seenProjects[id] {
+			seenProjects[id] = true
+			projectID := id
+			mutes = append(mutes, models.NotificationMute{UserID: userID, ProjectID: &projectID})
+		}
+	}
+
+	if len(seenProjects) > 0 {
+		var count int64
+		database.GetDB().Model(&models.Project{}).Where("id IN ?", req.MutedProjectIDs).Count(&count)
+		if int(count) != len(seenProjects) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Project not found"})
+			return
+		}
+	}
+
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.NotificationMute{}).Error; err != nil {
+			return err
+		}
+		if len(mutes) == 0 {
+			return nil
+		}
+		return tx.Create(&mutes).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification preferences"})
+		return
+	}
+
+	prefs, err := loadNotificationPreferences(database.GetDB(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notification preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
+
+func loadNotificationPreferences(db *gorm.DB, userID uint) (NotificationPreferences, error) {
+	prefs := NotificationPreferences{MutedTypes: []string{}, MutedProjectIDs: []uint{}}
+
+	var mutes []models.NotificationMute
+	if err := db.Where("user_id = ?", userID).Order("id").Find(&mutes).Error; err != nil {
+		return prefs, err
+	}
+
+	for _, m := range mutes {
+		if m.ProjectID != nil {
+			prefs.MutedProjectIDs = append(prefs.MutedProjectIDs, *m.ProjectID)
+		} else {
+			prefs.MutedTypes = append(prefs.MutedTypes, m.Type)
+		}
+	}
+	return prefs, nil
+}
+
+// teamWith returns the IDs of the project owner and the accepted members whose
+// role grants perm.
+func teamWith(tx *gorm.DB, project models.Project, perm models.Permission) ([]uint, error) {
+	var members []models.ProjectMember
+	err := tx.Select("user_id", "role").
+		Where("project_id = ? AND status = ?", project.ID, models.MemberStatusAccepted).
+		Find(&members).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []uint{project.OwnerID}
+	for _, m := range members {
+		if models.RoleCan(m.Role, perm) {
+			ids = append(ids, m.UserID)
+		}
+	}
+	return ids, nil
+}
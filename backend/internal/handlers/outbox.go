@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"project-exchange/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// recordOutbox writes an outbox event for background jobs. Call it with the
+// transaction that makes the change.
+func recordOutbox(tx *gorm.DB, eventType string, projectID, actorID, subjectID uint) error {
+	return tx.Create(&models.OutboxEvent{
+		Type:      eventType,
+		ProjectID: projectID,
+		ActorID:   actorID,
+		SubjectID: subjectID,
+	}).Error
+}
@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"project-exchange/internal/database"
+	"project-exchange/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	errNoPendingTransfer = errors.New("There is no pending ownership transfer")
+	errNomineeNotMember  = errors.New("Nominee is no longer a member of this project")
+	errOwnerChanged      = errors.New("Project owner has changed since the transfer was requested")
+)
+
+type TransferOwnershipRequest struct {
+	UserID uint `json:"user_id" binding:"required"`
+}
+
+// @Summary Nominate a new owner
+// @Description Start an ownership transfer to an accepted member (only project owner). The nominee has to accept it; a previous pending nomination is cancelled
+// @Tags ownership
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Project ID"
+// @Param transfer body TransferOwnershipRequest true "Nominee"
+// @Success 201 {object} models.OwnershipTransfer
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /projects/{id}/transfer [post]
+func RequestOwnershipTransfer(c *gin.Context) {
+	project, ok := authorizeProject(c, models.PermTransferOwnership, "transfer ownership")
+	if !ok {
+		return
+	}
+
+	var req TransferOwnershipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.UserID == project.OwnerID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You already own this project"})
+		return
+	}
+
+	if !isAcceptedMember(database.GetDB(), project.ID, req.UserID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ownership can only be transferred to an accepted member"})
+		return
+	}
+
+	transfer := models.OwnershipTransfer{
+		ProjectID:  project.ID,
+		FromUserID: project.OwnerID,
+		ToUserID:   req.UserID,
+		Status:     models.TransferStatusPending,
+	}
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := closePendingTransfer(tx, project.ID, models.TransferStatusCancelled); err != nil {
+			return err
+		}
+		if err := tx.Create(&transfer).Error; err != nil {
+			return err
+		}
+		return recordProjectEvent(tx, project.ID, project.OwnerID, models.ProjectEventOwnershipRequested,
+			formatUserID(project.OwnerID), formatUserID(req.UserID))
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to request ownership transfer"})
+		return
+	}
+
+	database.GetDB().Preload("FromUser").Preload("ToUser").First(&transfer, transfer.ID)
+
+	c.JSON(http.StatusCreated, transfer)
+}
+
+// @Summary Get pending ownership transfer
+// @Description Get the project's pending ownership transfer, if any (team members only)
+// @Tags ownership
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Project ID"
+// @Success 200 {object} models.OwnershipTransfer
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /projects/{id}/transfer [get]
+func GetOwnershipTransfer(c *gin.Context) {
+	project, ok := authorizeProject(c, models.PermViewProject, "view ownership transfers")
+	if !ok {
+		return
+	}
+
+	var transfer models.OwnershipTransfer
+	err := database.GetDB().Preload("FromUser").Preload("ToUser").
+		Where("project_id = ? AND status = ?", project.ID, models.TransferStatusPending).
+		First(&transfer).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": errNoPendingTransfer.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+// @Summary Cancel ownership transfer
+// @Description Cancel the pending ownership transfer (only project owner)
+// @Tags ownership
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Success 204
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /projects/{id}/transfer [delete]
+func CancelOwnershipTransfer(c *gin.Context) {
+	project, ok := authorizeProject(c, models.PermTransferOwnership, "cancel ownership transfers")
+	if !ok {
+		return
+	}
+
+	var transfer models.OwnershipTransfer
+	if err := database.GetDB().Where("project_id = ? AND status = ?", project.ID, models.TransferStatusPending).First(&transfer).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": errNoPendingTransfer.Error()})
+		return
+	}
+
+	if err := closePendingTransfer(database.GetDB(), project.ID, models.TransferStatusCancelled); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel ownership transfer"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Accept ownership
+// @Description Accept the pending ownership transfer addressed to you. You become the owner and the former owner stays on as a member
+// @Tags ownership
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Project ID"
+// @Success 200 {object} models.Project
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /projects/{id}/transfer/accept [post]
+func AcceptOwnershipTransfer(c *gin.Context) {
+	project, transfer, ok := loadMyTransfer(c)
+	if !ok {
+		return
+	}
+
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if !isAcceptedMember(tx, project.ID, transfer.ToUserID) {
+			return errNomineeNotMember
+		}
+
+		if err := respondToTransfer(tx, &transfer, models.TransferStatusAccepted); err != nil {
+			return err
+		}
+
+		// Guard against the owner changing in the meantime
+		res := tx.Model(&models.Project{}).
+			Where("id = ? AND owner_id = ?", project.ID, transfer.FromUserID).
+			Update("owner_id", transfer.ToUserID)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errOwnerChanged
+		}
+
+		// The new owner's membership ends; ownership supersedes it
+		var nominee models.ProjectMember
+		if err := tx.Where("project_id = ? AND user_id = ?", project.ID, transfer.ToUserID).First(&nominee).Error; err != nil {
+			return err
+		}
+		if err := setMemberStatus(tx, &nominee, models.MemberStatusLeft, transfer.ToUserID); err != nil {
+			return err
+		}
+
+		// The former owner stays on as a regular member
+		if _, err := admitMember(tx, project.ID, transfer.FromUserID, nil, true); err != nil {
+			return err
+		}
+
+		return recordProjectEvent(tx, project.ID, transfer.ToUserID, models.ProjectEventOwnershipTransferred,
+			formatUserID(transfer.FromUserID), formatUserID(transfer.ToUserID))
+	})
+	switch {
+	case errors.Is(err, errNomineeNotMember), errors.Is(err, errOwnerChanged), errors.Is(err, errNoPendingTransfer):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer ownership"})
+		return
+	}
+
+	database.GetDB().Scopes(withMemberCount).Preload("Owner").First(&project, project.ID)
+
+	c.JSON(http.StatusOK, project)
+}
+
+// @Summary Decline ownership
+// @Description Decline the pending ownership transfer addressed to you
+// @Tags ownership
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Success 204
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /projects/{id}/transfer/decline [post]
+func DeclineOwnershipTransfer(c *gin.Context) {
+	_, transfer, ok := loadMyTransfer(c)
+	if !ok {
+		return
+	}
+
+	err := respondToTransfer(database.GetDB(), &transfer, models.TransferStatusDeclined)
+	if errors.Is(err, errNoPendingTransfer) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decline ownership transfer"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// loadMyTransfer loads the project from the :id parameter and its pending
+// transfer addressed to the authenticated user, writing an error response if
+// there is none.
+func loadMyTransfer(c *gin.Context) (models.Project, models.OwnershipTransfer, bool) {
+	var project models.Project
+	var transfer models.OwnershipTransfer
+
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return project, transfer, false
+	}
+
+	if err := database.GetDB().First(&project, projectID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return project, transfer, false
+	}
+
+	err = database.GetDB().
+		Where("project_id = ? AND to_user_id = ? AND status = ?", project.ID, c.GetUint("user_id"), models.TransferStatusPending).
+		First(&transfer).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": errNoPendingTransfer.Error()})
+		return project, transfer, false
+	}
+
+	return project, transfer, true
+}
+
+// respondToTransfer moves a pending transfer to its final status, guarding
+// against a concurrent response.
+func respondToTransfer(tx *gorm.DB, transfer *models.OwnershipTransfer, status string) error {
+	now := time.Now()
+	res := tx.Model(&models.OwnershipTransfer{}).
+		Where("id = ? AND status = ?", transfer.ID, models.TransferStatusPending).
+		Updates(map[string]interface{}{"status": status, "responded_at": now})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errNoPendingTransfer
+	}
+
+	transfer.Status = status
+	transfer.RespondedAt = &now
+	return nil
+}
+
+// closePendingTransfer settles the project's pending transfer, if any, with
+// the given status.
+func closePendingTransfer(tx *gorm.DB, projectID uint, status string) error {
+	return tx.Model(&models.OwnershipTransfer{}).
+		Where("project_id = ? AND status = ?", projectID, models.TransferStatusPending).
+		Updates(map[string]interface{}{"status": status, "responded_at": time.Now()}).Error
+}
+
+// isAcceptedMember reports whether the user holds an accepted membership of
+// the project.
+func isAcceptedMember(db *gorm.DB, projectID, userID uint) bool {
+	var count int64
+	db.Model(&models.ProjectMember{}).
+		Where("project_id = ? AND user_id = ? AND status = ?", projectID, userID, models.MemberStatusAccepted).
+		Count(&count)
+	return count > 0
+}
+
+func formatUserID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// Page is the envelope returned by list endpoints. NextCursor is empty on the
+// last page; Total is only filled in when the client asks for it.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor"`
+	Total      *int64 `json:"total,omitempty"`
+}
+
+// sortOption describes one whitelisted value of the "sort" query parameter.
+// Rows are ordered by Key (if any) and then by id in the same direction, so
+// every page boundary can be expressed as a keyset cursor.
+type sortOption struct {
+	Key  string // SQL expression of the primary sort key; empty sorts by id alone
+	Desc bool
+
+	// SQL giving the sort key of the row whose id it is passed. When set, the
+	// key of the cursor row is looked up instead of being carried in the
+	// cursor, for keys such as timestamps that don't round-trip as a float.
+	KeyOfID string
+}
+
+// Sort options shared by endpoints that only order by creation.
+var (
+	sortNewest = sortOption{Desc: true}
+	sortOldest = sortOption{}
+
+	creationSorts = map[string]sortOption{
+		"newest": sortNewest,
+		"oldest": sortOldest,
+	}
+)
+
+// pageCursor is the decoded form of the opaque cursor handed to clients. It
+// holds the sort key and id of the last row of the previous page.
+type pageCursor struct {
+	Sort  string  `json:"s"`
+	Value float64 `json:"v,omitempty"`
+	ID    uint    `json:"id"`
+}
+
+type pageParams struct {
+	Limit     int
+	Cursor    *pageCursor
+	WithTotal bool
+	SortName  string
+	Sort      sortOption
+}
+
+// parsePageParams reads the limit, cursor, sort and total query parameters.
+// sorts whitelists the accepted sort values; defaultSort is used when the
+// client does not pick one.
+func parsePageParams(c *gin.Context, sorts map[string]sortOption, defaultSort string) (pageParams, error) {
+	params := pageParams{SortName: defaultSort}
+
+	limit, err := parseLimit(c, defaultPageLimit, maxPageLimit)
+	if err != nil {
+		return params, err
+	}
+	params.Limit = limit
+
+	if raw := c.Query("sort"); raw != "" {
+		params.SortName = raw
+	}
+	sort, ok := sorts[params.SortName]
+	if !ok {
+		return params, fmt.Errorf("Invalid sort %q", params.SortName)
+	}
+	params.Sort = sort
+
+	if raw := c.Query("cursor"); raw != "" {
+		cursor, err := decodeCursor(raw)
+		if err != nil || cursor.Sort != params.SortName {
+			return params, errors.New("Invalid cursor")
+		}
+		params.Cursor = cursor
+	}
+
+	params.WithTotal = c.Query("total") == "true"
+
+	return params, nil
+}
+
+// parseLimit reads the "limit" query parameter, clamping it to max.
+func parseLimit(c *gin.Context, fallback, max int) (int, error) {
+	raw := c.Query("limit")
+	if raw == "" {
+		return fallback, nil
+	}
+
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 {
+		return 0, errors.New("Invalid limit")
+	}
+	if limit > max {
+		limit = max
+	}
+	return limit, nil
+}
+
+// apply restricts query to the requested page. One extra row is fetched to
+// find out whether another page follows.
+func (p pageParams) apply(query *gorm.DB, idColumn string) *gorm.DB {
+	op, dir := ">", "asc"
+	if p.Sort.Desc {
+		op, dir = "<", "desc"
+	}
+
+	if p.Cursor != nil {
+		if p.Sort.Key == "" {
+			query = query.Where(idColumn+" "+op+" ?", p.Cursor.ID)
+		} else {
+			var value interface{} = p.Cursor.Value
+			if p.Sort.KeyOfID != "" {
+				value = gorm.Expr(p.Sort.KeyOfID, p.Cursor.ID)
+			}
+			query = query.Where(
+				fmt.Sprintf("(%[1]s %[2]s ?) OR (%[1]s = ? AND %[3]s %[2]s ?)", p.Sort.Key, op, idColumn),
+				value, value, p.Cursor.ID,
+			)
+		}
+	}
+
+	if p.Sort.Key != "" {
+		query = query.Order(p.Sort.Key + " " + dir)
+	}
+	return query.Order(idColumn + " " + dir).Limit(p.Limit + 1)
+}
+
+// count returns the total number of rows matching query when requested.
+func (p pageParams) count(query *gorm.DB) (*int64, error) {
+	if !p.WithTotal {
+		return nil, nil
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+	return &total, nil
+}
+
+// newPage trims the look-ahead row and builds the cursor for the next page.
+// keyOf returns the sort key value and id of an item.
+func newPage[T any](items []T, params pageParams, total *int64, keyOf func(T) (float64, uint)) Page[T] {
+	page := Page[T]{Items: items, Total: total}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+
+	if len(items) > params.Limit {
+		page.Items = items[:params.Limit]
+		value, id := keyOf(page.Items[len(page.Items)-1])
+		page.NextCursor = encodeCursor(pageCursor{Sort: params.SortName, Value: value, ID: id})
+	}
+
+	return page
+}
+
+func encodeCursor(cursor pageCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(raw string) (*pageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor pageCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
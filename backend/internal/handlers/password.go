@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"project-exchange/internal/config"
+	"project-exchange/internal/database"
+	"project-exchange/internal/mailer"
+	"project-exchange/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+func passwordResetTTL() time.Duration {
+	return config.Duration("PASSWORD_RESET_TTL", time.Hour)
+}
+
+// appURL is the frontend base URL used to build links in emails.
+func appURL(path string) string {
+	return strings.TrimRight(config.String("APP_URL", "http://localhost:3000"), "/") + path
+}
+
+// @Summary Request password reset
+// @Description Email a password reset link. The response is the same whether or not the email is registered.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body ForgotPasswordRequest true "Account email"
+// @Success 202 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /auth/password/forgot [post]
+func ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := gin.H{"message": "If the email is registered, a reset link has been sent"}
+
+	var user models.User
+	if err := database.GetDB().Where("email = ?", req.Email).First(&user).Error; err != nil {
+		c.JSON(http.StatusAccepted, response)
+		return
+	}
+
+	token, err := newRandomToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	reset := models.PasswordReset{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(passwordResetTTL()),
+	}
+	if err := database.GetDB().Create(&reset).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create reset token"})
+		return
+	}
+
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to choose a new password. It expires in %s.\n\n%s\n\nIf you did not request this, you can ignore this email.\n",
+			user.Name, passwordResetTTL(), appURL("/reset-password?token="+token)),
+	}
+	// Send in the background so the response time does not tell registered
+	// addresses apart from unknown ones
+	m := mailer.GetMailer()
+	go func() {
+		if err := m.Send(msg); err != nil {
+			log.Println("Failed to send password reset email:", err)
+		}
+	}()
+
+	c.JSON(http.StatusAccepted, response)
+}
+
+// @Summary Reset password
+// @Description Set a new password using a token from the reset email. Signs the user out everywhere.
+// @Tags auth
+// @Accept json
+// @Param request body ResetPasswordRequest true "Reset token and new password"
+// @Success 204
+// @Failure 400 {object} map[string]interface{}
+// @Router /auth/password/reset [post]
+func ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		var reset models.PasswordReset
+		if err := tx.Where("token_hash = ?", hashToken(req.Token)).First(&reset).Error; err != nil {
+			return err
+		}
+		if time.Now().After(reset.ExpiresAt) {
+			return gorm.ErrRecordNotFound
+		}
+
+		// Claim the token; a concurrent request that already used it wins
+		now := time.Now()
+		res := tx.Model(&models.PasswordReset{}).
+			Where("id = ? AND used_at IS NULL", reset.ID).
+			Update("used_at", now)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Model(&models.User{}).Where("id = ?", reset.UserID).
+			Updates(map[string]interface{}{"password_hash": string(hashedPassword), "credentials_changed_at": now}).Error; err != nil {
+			return err
+		}
+
+		// Burn any other outstanding reset links for this user
+		if err := tx.Model(&models.PasswordReset{}).
+			Where("user_id = ? AND used_at IS NULL", reset.UserID).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+
+		return revokeUserTokens(tx, reset.UserID)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
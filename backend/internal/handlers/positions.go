@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"project-exchange/internal/database"
+	"project-exchange/internal/models"
+	"project-exchange/internal/skills"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const maxPositionSeats = 50
+
+var (
+	errPositionFull = errors.New("Position is already filled")
+	errPositionGone = errors.New("Position no longer exists")
+)
+
+type PositionInput struct {
+	Role   string   `json:"role" binding:"required"`
+	Skills []string `json:"skills"`
+	Level  string   `json:"level"` // beginner/middle/expert
+	Seats  int      `json:"seats"` // defaults to 1
+}
+
+// validatePosition checks and normalizes a position definition.
+func validatePosition(input *PositionInput) error {
+	input.Role = strings.TrimSpace(input.Role)
+	if input.Role == "" {
+		return errors.New("Position role is required")
+	}
+
+	if input.Level != "" && !models.IsLevel(input.Level) {
+		return fmt.Errorf("Position %q: level must be beginner, middle or expert", input.Role)
+	}
+
+	if input.Seats == 0 {
+		input.Seats = 1
+	}
+	if input.Seats < 1 || input.Seats > maxPositionSeats {
+		return fmt.Errorf("Position %q: seats must be between 1 and %d", input.Role, maxPositionSeats)
+	}
+
+	// Store canonical spellings so positions match user skills
+	names := make([]string, 0, len(input.Skills))
+	for _, skill := range input.Skills {
+		if strings.TrimSpace(skill) == "" {
+			continue
+		}
+		if name := skills.Canonical(database.GetDB(), skill); !containsString(names, name) {
+			names = append(names, name)
+		}
+	}
+	input.Skills = names
+
+	return nil
+}
+
+// seatsTaken counts the accepted members holding a position.
+func seatsTaken(tx *gorm.DB, positionID uint) (int64, error) {
+	var taken int64
+	err := tx.Model(&models.ProjectMember{}).
+		Where("position_id = ? AND status = ?", positionID, models.MemberStatusAccepted).
+		Count(&taken).Error
+	return taken, err
+}
+
+// ensureSeatFree returns errPositionFull if every seat of the position is
+// taken, and errPositionGone if the position has been deleted.
+func ensureSeatFree(tx *gorm.DB, positionID uint) error {
+	var position models.ProjectPosition
+	if err := tx.First(&position, positionID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+		return errPositionGone
+	} else if err != nil {
+		return err
+	}
+	taken, err := seatsTaken(tx, position.ID)
+	if err != nil {
+		return err
+	}
+	if taken >= int64(position.Seats) {
+		return errPositionFull
+	}
+	return nil
+}
+
+// refreshPositionFilled marks the position filled once its seats run out and
+// reopens it when a seat frees up.
+func refreshPositionFilled(tx *gorm.DB, positionID uint) error {
+	var position models.ProjectPosition
+	if err := tx.First(&position, positionID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+		return errPositionGone
+	} else if err != nil {
+		return err
+	}
+
+	taken, err := seatsTaken(tx, positionID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Model(&position).Update("filled", taken >= int64(position.Seats)).Error
+}
+
+// loadPositions returns the project's positions with their taken seats.
+func loadPositions(db *gorm.DB, projectID uint) ([]models.ProjectPosition, error) {
+	var positions []models.ProjectPosition
+	err := withSeatsTaken(db.Model(&models.ProjectPosition{})).
+		Where("project_id = ?", projectID).
+		Find(&positions).Error
+	return positions, err
+}
+
+// withSeatsTaken selects positions in creation order along with their
+// seats_taken count. It doubles as a Preload scope.
+func withSeatsTaken(db *gorm.DB) *gorm.DB {
+	return db.Select("project_positions.*, (SELECT COUNT(*) FROM project_members WHERE project_members.position_id = project_positions.id AND project_members.status = ?) AS seats_taken", models.MemberStatusAccepted).
+		Order("project_positions.id asc")
+}
+
+func createPositions(tx *gorm.DB, projectID uint, inputs []PositionInput) error {
+	for _, input := range inputs {
+		position := models.ProjectPosition{
+			ProjectID: projectID,
+			Role:      input.Role,
+			Skills:    input.Skills,
+			Level:     input.Level,
+			Seats:     input.Seats,
+		}
+		if err := tx.Create(&position).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// @Summary Add project position
+// @Description Add an open position with required skills, level and seat count (owner or maintainer)
+// @Tags positions
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Project ID"
+// @Param position body PositionInput true "Position data"
+// @Success 201 {object} models.ProjectPosition
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /projects/{id}/positions [post]
+func CreatePosition(c *gin.Context) {
+	project, ok := authorizeProject(c, models.PermEditProject, "manage positions")
+	if !ok {
+		return
+	}
+
+	var req PositionInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validatePosition(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	position := models.ProjectPosition{
+		ProjectID: project.ID,
+		Role:      req.Role,
+		Skills:    req.Skills,
+		Level:     req.Level,
+		Seats:     req.Seats,
+	}
+	if err := database.GetDB().Create(&position).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create position"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, position)
+}
+
+// @Summary Update project position
+// @Description Update a position (owner or maintainer). Seats cannot drop below the number of accepted members holding it.
+// @Tags positions
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Project ID"
+// @Param positionId path int true "Position ID"
+// @Param position body PositionInput true "Position data"
+// @Success 200 {object} models.ProjectPosition
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /projects/{id}/positions/{positionId} [put]
+func UpdatePosition(c *gin.Context) {
+	project, ok := authorizeProject(c, models.PermEditProject, "manage positions")
+	if !ok {
+		return
+	}
+
+	position, ok := loadProjectPosition(c, project.ID)
+	if !ok {
+		return
+	}
+
+	var req PositionInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validatePosition(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	taken, err := seatsTaken(database.GetDB(), position.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update position"})
+		return
+	}
+	if int64(req.Seats) < taken {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Position already has %d accepted members", taken)})
+		return
+	}
+
+	position.Role = req.Role
+	position.Skills = req.Skills
+	position.Level = req.Level
+	position.Seats = req.Seats
+	position.Filled = taken >= int64(req.Seats)
+
+	if err := database.GetDB().Save(&position).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update position"})
+		return
+	}
+
+	position.SeatsTaken = &taken
+	c.JSON(http.StatusOK, position)
+}
+
+// @Summary Delete project position
+// @Description Delete a position nobody has applied to or holds (owner or maintainer)
+// @Tags positions
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param positionId path int true "Position ID"
+// @Success 204
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /projects/{id}/positions/{positionId} [delete]
+func DeletePosition(c *gin.Context) {
+	project, ok := authorizeProject(c, models.PermEditProject, "manage positions")
+	if !ok {
+		return
+	}
+
+	position, ok := loadProjectPosition(c, project.ID)
+	if !ok {
+		return
+	}
+
+	var active int64
+	database.GetDB().Model(&models.ProjectMember{}).
+		Where("position_id = ? AND status IN ?", position.ID, []string{models.MemberStatusPending, models.MemberStatusAccepted}).
+		Count(&active)
+	if active > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Position has pending applications or members"})
+		return
+	}
+
+	// Former members, invitations and invite links fall back to no position
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		for _, model := range []interface{}{&models.ProjectMember{}, &models.Invitation{}, &models.InviteLink{}} {
+			if err := tx.Model(model).Where("position_id = ?", position.ID).Update("position_id", nil).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&position).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete position"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func loadProjectPosition(c *gin.Context, projectID uint) (models.ProjectPosition, bool) {
+	var position models.ProjectPosition
+
+	positionID, err := strconv.ParseUint(c.Param("positionId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid position ID"})
+		return position, false
+	}
+
+	if err := database.GetDB().Where("id = ? AND project_id = ?", positionID, projectID).First(&position).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Position not found"})
+		return position, false
+	}
+
+	return position, true
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"project-exchange/internal/database"
+	"project-exchange/internal/models"
+	"project-exchange/internal/webhooks"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type UpdateProjectStatusRequest struct {
+	Status string `json:"status" binding:"required"` // draft/open/in_progress/completed/archived
+}
+
+var errProjectStatusConflict = errors.New("project status changed concurrently")
+
+// @Summary Change project status
+// @Description Move a project through its lifecycle (only project owner). Allowed: draft→open|archived, open→draft|in_progress|completed|archived, in_progress→open|completed|archived, completed→in_progress|archived, archived→completed.
+// @Tags projects
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Project ID"
+// @Param status body UpdateProjectStatusRequest true "New status"
+// @Success 200 {object} models.Project
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /projects/{id}/status [post]
+func UpdateProjectStatus(c *gin.Context) {
+	project, ok := authorizeProject(c, models.PermChangeStatus, "change the project status")
+	if !ok {
+		return
+	}
+	userID := c.GetUint("user_id")
+
+	var req UpdateProjectStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !models.IsProjectStatus(req.Status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+		return
+	}
+
+	from := project.Status
+	if !models.CanTransitionProject(from, req.Status) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Cannot change project status from " + from + " to " + req.Status})
+		return
+	}
+
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.Project{}).
+			Where("id = ? AND status = ?", project.ID, from).
+			Update("status", req.Status)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errProjectStatusConflict
+		}
+		if err := recordProjectEvent(tx, project.ID, userID, models.ProjectEventStatusChanged, from, req.Status); err != nil {
+			return err
+		}
+
+		if req.Status == models.ProjectStatusOpen {
+			if err := recordOutbox(tx, models.OutboxProjectPublished, project.ID, userID, project.ID); err != nil {
+				return err
+			}
+		}
+
+		project.Status = req.Status
+		return webhooks.Enqueue(tx, project.ID, models.WebhookEventProjectUpdated, webhooks.NewProjectData(project))
+	})
+	if errors.Is(err, errProjectStatusConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Project status was changed by someone else, please retry"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change project status"})
+		return
+	}
+
+	// Load the owner data
+	database.GetDB().Scopes(withMemberCount).Preload("Owner").First(&project, project.ID)
+
+	c.JSON(http.StatusOK, project)
+}
+
+// @Summary Get project history
+// @Description List changes made to the project, newest first (project owner and accepted members)
+// @Tags projects
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Project ID"
+// @Param sort query string false "Sort order (newest/oldest)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from a previous page"
+// @Param total query bool false "Include the total count"
+// @Success 200 {object} Page[models.ProjectEvent]
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /projects/{id}/history [get]
+func GetProjectHistory(c *gin.Context) {
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var project models.Project
+	if err := database.GetDB().First(&project, projectID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+
+	if !can(project, userID.(uint), models.PermViewProject) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to view the history"})
+		return
+	}
+
+	params, err := parsePageParams(c, creationSorts, "newest")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := database.GetDB().Model(&models.ProjectEvent{}).Where("project_id = ?", project.ID)
+
+	total, err := params.count(query.Session(&gorm.Session{}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch project history"})
+		return
+	}
+
+	var events []models.ProjectEvent
+	if err := params.apply(query, "id").Preload("Actor").Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch project history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newPage(events, params, total, func(e models.ProjectEvent) (float64, uint) {
+		return 0, e.ID
+	}))
+}
+
+func recordProjectEvent(tx *gorm.DB, projectID, actorID uint, eventType, from, to string) error {
+	return tx.Create(&models.ProjectEvent{
+		ProjectID: projectID,
+		ActorID:   actorID,
+		Type:      eventType,
+		From:      from,
+		To:        to,
+	}).Error
+}
@@ -3,18 +3,24 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"project-exchange/internal/database"
 	"project-exchange/internal/models"
+	"project-exchange/internal/webhooks"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type CreateProjectRequest struct {
-	Title       string `json:"title" binding:"required"`
-	Description string `json:"description"`
-	Category    string `json:"category"`
-	Level       string `json:"level"` // beginner/middle/expert
+	Title       string          `json:"title" binding:"required"`
+	Description string          `json:"description"`
+	Category    string          `json:"category"`
+	Level       string          `json:"level"`  // beginner/middle/expert
+	Status      string          `json:"status"` // draft or open (default)
+	Questions   []QuestionInput `json:"questions"`
+	Positions   []PositionInput `json:"positions"`
 }
 
 type UpdateProjectRequest struct {
@@ -48,56 +54,204 @@ func CreateProject(c *gin.Context) {
 		return
 	}
 
+	if err := validateQuestions(req.Questions); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for i := range req.Positions {
+		if err := validatePosition(&req.Positions[i]); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	// New projects start as drafts or open for applications
+	switch req.Status {
+	case "":
+		req.Status = models.ProjectStatusOpen
+	case models.ProjectStatusDraft, models.ProjectStatusOpen:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "New projects must be draft or open"})
+		return
+	}
+
 	project := models.Project{
 		OwnerID:     userID.(uint),
 		Title:       req.Title,
 		Description: req.Description,
 		Category:    req.Category,
 		Level:       req.Level,
+		Status:      req.Status,
 	}
 
-	if err := database.GetDB().Create(&project).Error; err != nil {
+	if err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&project).Error; err != nil {
+			return err
+		}
+		if err := createPositions(tx, project.ID, req.Positions); err != nil {
+			return err
+		}
+		if err := replaceQuestions(tx, project.ID, req.Questions); err != nil {
+			return err
+		}
+		if project.Status != models.ProjectStatusOpen {
+			return nil
+		}
+		return recordOutbox(tx, models.OutboxProjectPublished, project.ID, project.OwnerID, project.ID)
+	}); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create project"})
 		return
 	}
 
 	// Load the owner data
-	database.GetDB().Preload("Owner").First(&project, project.ID)
+	database.GetDB().Scopes(withMemberCount).Preload("Owner").Preload("Questions", orderQuestions).Preload("Positions").First(&project, project.ID)
 
 	c.JSON(http.StatusCreated, project)
 }
 
+// memberCountSQL counts the accepted members of the project in the outer query.
+const memberCountSQL = "(SELECT COUNT(*) FROM project_members WHERE project_members.project_id = projects.id AND project_members.status = 'accepted')"
+
+// withMemberCount fills MemberCount on the loaded projects.
+func withMemberCount(db *gorm.DB) *gorm.DB {
+	return db.Select("projects.*, " + memberCountSQL + " AS member_count")
+}
+
+var projectSorts = map[string]sortOption{
+	"newest":  sortNewest,
+	"oldest":  sortOldest,
+	"members": {Key: memberCountSQL, Desc: true},
+}
+
+// Search results can also be ordered by relevance; hits are paged from a
+// subquery aliased "hits", so the sort keys refer to its columns.
+var projectSearchSorts = map[string]sortOption{
+	"relevance": {Key: "hits.rank"},
+	"newest":    sortNewest,
+	"oldest":    sortOldest,
+}
+
 // @Summary Get projects
-// @Description Get list of projects with optional filtering
+// @Description Get a page of projects with optional filtering and full-text search. Search results carry highlighted snippets and are ordered by relevance unless another sort is requested.
 // @Tags projects
 // @Produce json
+// @Param q query string false "Full-text search over title, description and category"
 // @Param category query string false "Filter by category"
 // @Param level query string false "Filter by level"
-// @Success 200 {array} models.Project
+// @Param status query string false "Filter by status (draft/open/in_progress/completed/archived); drafts are only visible to their owner"
+// @Param sort query string false "Sort order (newest/oldest/members, or relevance when searching)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from a previous page"
+// @Param total query bool false "Include the total count"
+// @Success 200 {object} Page[models.Project]
+// @Failure 400 {object} map[string]interface{}
 // @Router /projects [get]
 func GetProjects(c *gin.Context) {
-	var projects []models.Project
-	query := database.GetDB().Preload("Owner")
+	query := database.GetDB().Model(&models.Project{})
 
 	// Apply filters
 	if category := c.Query("category"); category != "" {
-		query = query.Where("category = ?", category)
+		query = query.Where("projects.category = ?", category)
 	}
 	if level := c.Query("level"); level != "" {
-		query = query.Where("level = ?", level)
+		query = query.Where("projects.level = ?", level)
+	}
+	if status := c.Query("status"); status != "" {
+		if !models.IsProjectStatus(status) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+			return
+		}
+		query = query.Where("projects.status = ?", status)
+	}
+
+	// Drafts are only listed for their owner
+	query = query.Where("projects.status <> ? OR projects.owner_id = ?", models.ProjectStatusDraft, c.GetUint("user_id"))
+
+	// Full-text search
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		searchProjectPage(c, query, q)
+		return
+	}
+
+	params, err := parsePageParams(c, projectSorts, "newest")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	total, err := params.count(query.Session(&gorm.Session{}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch projects"})
+		return
 	}
 
-	if err := query.Find(&projects).Error; err != nil {
+	var projects []models.Project
+	if err := params.apply(query, "projects.id").
+		Scopes(withMemberCount).
+		Preload("Owner").
+		Find(&projects).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch projects"})
 		return
 	}
 
-	c.JSON(http.StatusOK, projects)
+	c.JSON(http.StatusOK, newPage(projects, params, total, func(p models.Project) (float64, uint) {
+		if params.Sort.Key == "" {
+			return 0, p.ID
+		}
+		return float64(p.MemberCount), p.ID
+	}))
+}
+
+// searchProjectPage writes a page of full-text search hits for the filtered
+// project query.
+func searchProjectPage(c *gin.Context, query *gorm.DB, q string) {
+	params, err := parsePageParams(c, projectSearchSorts, "relevance")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	match := buildMatchQuery(q)
+	if match == "" {
+		c.JSON(http.StatusOK, newPage([]searchHit{}, params, nil, hitKey))
+		return
+	}
+
+	hitsQuery := database.GetDB().Table("(?) AS hits", searchProjects(query, match))
+
+	total, err := params.count(hitsQuery.Session(&gorm.Session{}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search projects"})
+		return
+	}
+
+	var hits []searchHit
+	if err := params.apply(hitsQuery, "hits.id").Scan(&hits).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search projects"})
+		return
+	}
+
+	// Build the cursor from the hits before they are swapped for projects
+	hitPage := newPage(hits, params, total, hitKey)
+
+	projects, err := loadSearchResults(database.GetDB(), hitPage.Items)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search projects"})
+		return
+	}
+
+	c.JSON(http.StatusOK, Page[models.Project]{
+		Items:      projects,
+		NextCursor: hitPage.NextCursor,
+		Total:      hitPage.Total,
+	})
 }
 
 // @Summary Get project
-// @Description Get project by ID with owner and members
+// @Description Get project by ID with owner and members. Only the owner and maintainers see pending and past applications.
 // @Tags projects
+// @Security BearerAuth
 // @Produce json
 // @Param id path int true "Project ID"
 // @Success 200 {object} models.Project
@@ -111,19 +265,39 @@ func GetProject(c *gin.Context) {
 	}
 
 	var project models.Project
-	if err := database.GetDB().
-		Preload("Owner").
-		Preload("Members.User").
-		First(&project, projectID).Error; err != nil {
+	if err := database.GetDB().Scopes(withMemberCount).Preload("Owner").Preload("Questions", orderQuestions).First(&project, projectID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+
+	role := projectRole(project, c.GetUint("user_id"))
+
+	// Drafts are private to their team
+	if project.Status == models.ProjectStatusDraft && role == "" {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
 		return
 	}
 
+	// Only reviewers see applicants; everyone else sees the accepted team
+	members := database.GetDB().Where("project_id = ?", project.ID)
+	if !models.RoleCan(role, models.PermReviewApplications) {
+		members = members.Where("status = ?", models.MemberStatusAccepted)
+	}
+	if err := members.Preload("User").Find(&project.Members).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch project members"})
+		return
+	}
+
+	if project.Positions, err = loadPositions(database.GetDB(), project.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch project positions"})
+		return
+	}
+
 	c.JSON(http.StatusOK, project)
 }
 
 // @Summary Update project
-// @Description Update project (only owner can update)
+// @Description Update project (owner or maintainer)
 // @Tags projects
 // @Security BearerAuth
 // @Accept json
@@ -136,27 +310,9 @@ func GetProject(c *gin.Context) {
 // @Failure 404 {object} map[string]interface{}
 // @Router /projects/{id} [put]
 func UpdateProject(c *gin.Context) {
-	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
-		return
-	}
-
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	var project models.Project
-	if err := database.GetDB().First(&project, projectID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
-		return
-	}
-
-	// Check if user is the owner
-	if project.OwnerID != userID.(uint) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Only project owner can update the project"})
+	// Owners and maintainers may edit the details
+	project, ok := authorizeProject(c, models.PermEditProject, "update the project")
+	if !ok {
 		return
 	}
 
@@ -174,13 +330,19 @@ func UpdateProject(c *gin.Context) {
 	project.Category = req.Category
 	project.Level = req.Level
 
-	if err := database.GetDB().Save(&project).Error; err != nil {
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&project).Error; err != nil {
+			return err
+		}
+		return webhooks.Enqueue(tx, project.ID, models.WebhookEventProjectUpdated, webhooks.NewProjectData(project))
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update project"})
 		return
 	}
 
 	// Load the owner data
-	database.GetDB().Preload("Owner").First(&project, project.ID)
+	database.GetDB().Scopes(withMemberCount).Preload("Owner").First(&project, project.ID)
 
 	c.JSON(http.StatusOK, project)
 }
@@ -195,27 +357,9 @@ func UpdateProject(c *gin.Context) {
 // @Failure 404 {object} map[string]interface{}
 // @Router /projects/{id} [delete]
 func DeleteProject(c *gin.Context) {
-	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
-		return
-	}
-
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	var project models.Project
-	if err := database.GetDB().First(&project, projectID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
-		return
-	}
-
-	// Check if user is the owner
-	if project.OwnerID != userID.(uint) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Only project owner can delete the project"})
+	// Maintainers can't delete the project
+	project, ok := authorizeProject(c, models.PermDeleteProject, "delete the project")
+	if !ok {
 		return
 	}
 
@@ -226,3 +370,7 @@ func DeleteProject(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+func orderQuestions(db *gorm.DB) *gorm.DB {
+	return db.Order("position asc")
+}
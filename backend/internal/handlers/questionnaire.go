@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"project-exchange/internal/database"
+	"project-exchange/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	maxQuestions       = 20
+	maxAnswerLength    = 2000
+	maxCoverLetterSize = 5000
+)
+
+type QuestionInput struct {
+	Type     string   `json:"type" binding:"required"` // text/single_choice/skill_rating
+	Prompt   string   `json:"prompt" binding:"required"`
+	Options  []string `json:"options"` // single_choice only
+	Skill    string   `json:"skill"`   // skill_rating only
+	Required bool     `json:"required"`
+}
+
+type UpdateQuestionsRequest struct {
+	Questions []QuestionInput `json:"questions"`
+}
+
+type AnswerInput struct {
+	QuestionID uint   `json:"question_id" binding:"required"`
+	Value      string `json:"value"`
+}
+
+type ApplyRequest struct {
+	PositionID  *uint         `json:"position_id"` // required when the project has positions
+	CoverLetter string        `json:"cover_letter"`
+	Answers     []AnswerInput `json:"answers"`
+}
+
+// validateQuestions checks a questionnaire definition before it is stored.
+func validateQuestions(questions []QuestionInput) error {
+	if len(questions) > maxQuestions {
+		return fmt.Errorf("A questionnaire can have at most %d questions", maxQuestions)
+	}
+
+	for i, q := range questions {
+		if strings.TrimSpace(q.Prompt) == "" {
+			return fmt.Errorf("Question %d: prompt is required", i+1)
+		}
+
+		switch q.Type {
+		case models.QuestionTypeText:
+		case models.QuestionTypeSingleChoice:
+			if len(q.Options) < 2 {
+				return fmt.Errorf("Question %d: single choice questions need at least two options", i+1)
+			}
+			seen := make(map[string]bool, len(q.Options))
+			for _, option := range q.Options {
+				if strings.TrimSpace(option) == "" || seen[option] {
+					return fmt.Errorf("Question %d: options must be non-empty and unique", i+1)
+				}
+				seen[option] = true
+			}
+		case models.QuestionTypeSkillRating:
+			if strings.TrimSpace(q.Skill) == "" {
+				return fmt.Errorf("Question %d: skill rating questions need a skill", i+1)
+			}
+		default:
+			return fmt.Errorf("Question %d: unknown type %q", i+1, q.Type)
+		}
+	}
+
+	return nil
+}
+
+// replaceQuestions swaps the project's questionnaire for the given one.
+func replaceQuestions(tx *gorm.DB, projectID uint, inputs []QuestionInput) error {
+	if err := tx.Where("project_id = ?", projectID).Delete(&models.ProjectQuestion{}).Error; err != nil {
+		return err
+	}
+
+	for i, input := range inputs {
+		question := models.ProjectQuestion{
+			ProjectID: projectID,
+			Position:  i + 1,
+			Type:      input.Type,
+			Prompt:    strings.TrimSpace(input.Prompt),
+			Required:  input.Required,
+		}
+		switch input.Type {
+		case models.QuestionTypeSingleChoice:
+			question.Options = input.Options
+		case models.QuestionTypeSkillRating:
+			question.Skill = strings.TrimSpace(input.Skill)
+		}
+
+		if err := tx.Create(&question).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateAnswers checks an application against the project's questionnaire
+// and returns the answers ready to be stored.
+func validateAnswers(questions []models.ProjectQuestion, inputs []AnswerInput) ([]models.ApplicationAnswer, error) {
+	byID := make(map[uint]models.ProjectQuestion, len(questions))
+	for _, q := range questions {
+		byID[q.ID] = q
+	}
+
+	answered := make(map[uint]bool, len(inputs))
+	answers := make([]models.ApplicationAnswer, 0, len(inputs))
+
+	for _, input := range inputs {
+		question, ok := byID[input.QuestionID]
+		if !ok {
+			return nil, fmt.Errorf("Question %d does not belong to this project", input.QuestionID)
+		}
+		if answered[input.QuestionID] {
+			return nil, fmt.Errorf("Question %d is answered more than once", input.QuestionID)
+		}
+
+		value := strings.TrimSpace(input.Value)
+		if value == "" {
+			// Blank answers count as unanswered
+			continue
+		}
+
+		switch question.Type {
+		case models.QuestionTypeText:
+			if len(value) > maxAnswerLength {
+				return nil, fmt.Errorf("Answer to %q is too long", question.Prompt)
+			}
+		case models.QuestionTypeSingleChoice:
+			if !containsString(question.Options, value) {
+				return nil, fmt.Errorf("Answer to %q must be one of the offered options", question.Prompt)
+			}
+		case models.QuestionTypeSkillRating:
+			rating, err := strconv.Atoi(value)
+			if err != nil || rating < models.SkillRatingMin || rating > models.SkillRatingMax {
+				return nil, fmt.Errorf("Rating for %q must be between %d and %d", question.Skill, models.SkillRatingMin, models.SkillRatingMax)
+			}
+		}
+
+		answered[input.QuestionID] = true
+		answers = append(answers, models.ApplicationAnswer{
+			QuestionID: question.ID,
+			Prompt:     question.Prompt,
+			Value:      value,
+		})
+	}
+
+	for _, q := range questions {
+		if q.Required && !answered[q.ID] {
+			return nil, fmt.Errorf("Question %q is required", q.Prompt)
+		}
+	}
+
+	return answers, nil
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// @Summary Update project questionnaire
+// @Description Replace the questionnaire applicants answer when applying (owner or maintainer). Send an empty list to remove it.
+// @Tags projects
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Project ID"
+// @Param questions body UpdateQuestionsRequest true "Questionnaire"
+// @Success 200 {array} models.ProjectQuestion
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /projects/{id}/questions [put]
+func UpdateProjectQuestions(c *gin.Context) {
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var project models.Project
+	if err := database.GetDB().First(&project, projectID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+
+	if !can(project, userID.(uint), models.PermEditProject) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to change the questionnaire"})
+		return
+	}
+
+	var req UpdateQuestionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validateQuestions(req.Questions); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		return replaceQuestions(tx, project.ID, req.Questions)
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update questionnaire"})
+		return
+	}
+
+	var questions []models.ProjectQuestion
+	database.GetDB().Where("project_id = ?", project.ID).Order("position asc").Find(&questions)
+
+	c.JSON(http.StatusOK, questions)
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"project-exchange/internal/database"
+	"project-exchange/internal/models"
+	"project-exchange/internal/realtime"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	// Authentication is token based and CORS is open, so any origin may connect.
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// @Summary Project chat socket
+// @Description Open a WebSocket that receives every new message posted to the project chat. The token may be passed as a "token" query parameter.
+// @Tags messages
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param token query string false "JWT token (alternative to the Authorization header)"
+// @Success 101
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /projects/{id}/ws [get]
+func ProjectChatSocket(c *gin.Context) {
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Check if project exists
+	var project models.Project
+	if err := database.GetDB().First(&project, projectID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+
+	// Anyone on the team may listen, including viewers
+	if !can(project, userID.(uint), models.PermViewProject) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to join the chat"})
+		return
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Println("WebSocket upgrade failed:", err)
+		return
+	}
+
+	realtime.GetHub().Serve(conn, project.ID, userID.(uint))
+}
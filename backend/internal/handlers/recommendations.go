@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"project-exchange/internal/database"
+	"project-exchange/internal/models"
+	"project-exchange/internal/skills"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Weights of the recommendation score components; they add up to 1.
+const (
+	weightSkills  = 0.5
+	weightLevel   = 0.2
+	weightRecency = 0.15
+	weightSeats   = 0.15
+)
+
+const (
+	defaultRecommendations = 10
+	maxRecommendations     = 50
+
+	// Only the most recent open projects are considered for ranking
+	recommendationPool = 500
+
+	recencyHalfLife   = 14 * 24 * time.Hour
+	seatsForFullScore = 5
+)
+
+// Recommendation is a project suggested to the user together with the score
+// it was ranked by and the reasons it was picked.
+type Recommendation struct {
+	Project       models.Project `json:"project"`
+	Score         float64        `json:"score"`
+	MatchedSkills []string       `json:"matched_skills"`
+	Reasons       []string       `json:"reasons"`
+}
+
+// @Summary Get project recommendations
+// @Description Rank open projects for the current user by skill overlap with open positions, level fit, recency and free seats. Own projects and projects the user applied to are left out
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Number of recommendations (default 10, max 50)"
+// @Success 200 {object} Page[Recommendation]
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /me/recommendations [get]
+func GetRecommendations(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	limit, err := parseLimit(c, defaultRecommendations, maxRecommendations)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := database.GetDB()
+
+	var user models.User
+	if err := db.Preload("SkillLevels.Skill").First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var projects []models.Project
+	err = db.Scopes(withMemberCount).Preload("Owner").
+		Preload("Positions", withSeatsTaken).
+		Where("status = ? AND owner_id <> ?", models.ProjectStatusOpen, userID).
+		Where("id NOT IN (?)", db.Model(&models.ProjectMember{}).Select("project_id").Where("user_id = ?", userID)).
+		Order("created_at desc").
+		Limit(recommendationPool).
+		Find(&projects).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch projects"})
+		return
+	}
+
+	userSkills := userSkillIndex(user.SkillLevels)
+	now := time.Now()
+
+	recommendations := make([]Recommendation, 0, len(projects))
+	for _, project := range projects {
+		if rec, ok := scoreProject(project, userSkills, now); ok {
+			recommendations = append(recommendations, rec)
+		}
+	}
+
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		return recommendations[i].Score > recommendations[j].Score
+	})
+	if len(recommendations) > limit {
+		recommendations = recommendations[:limit]
+	}
+
+	c.JSON(http.StatusOK, Page[Recommendation]{Items: recommendations})
+}
+
+// scoreProject rates a project for a user. Projects whose positions are all
+// filled, or whose required skills don't overlap the user's at all, are
+// rejected.
+func scoreProject(project models.Project, userSkills map[string]models.UserSkill, now time.Time) (Recommendation, bool) {
+	rec := Recommendation{Project: project, MatchedSkills: []string{}}
+
+	// Skills of positions that still have free seats
+	var required []string
+	var best *models.ProjectPosition
+	bestMatches, freeSeats := 0, 0
+	for i, position := range project.Positions {
+		free := position.Seats - int(derefInt64(position.SeatsTaken))
+		if position.Filled || free <= 0 {
+			continue
+		}
+		freeSeats += free
+
+		matches := 0
+		for _, skill := range position.Skills {
+			if !containsString(required, skill) {
+				required = append(required, skill)
+			}
+			if _, ok := userSkills[skills.Normalize(skill)]; ok {
+				matches++
+			}
+		}
+		if best == nil || matches > bestMatches {
+			best, bestMatches = &project.Positions[i], matches
+		}
+	}
+	if len(project.Positions) > 0 && freeSeats == 0 {
+		return rec, false
+	}
+
+	var matchedLevels []string
+	for _, skill := range required {
+		if userSkill, ok := userSkills[skills.Normalize(skill)]; ok {
+			rec.MatchedSkills = append(rec.MatchedSkills, skill)
+			matchedLevels = append(matchedLevels, userSkill.Level)
+		}
+	}
+	if len(required) > 0 && len(rec.MatchedSkills) == 0 {
+		return rec, false
+	}
+
+	skillScore := 0.0
+	if len(required) > 0 {
+		skillScore = float64(len(rec.MatchedSkills)) / float64(len(required))
+		rec.Reasons = append(rec.Reasons, fmt.Sprintf("Matches your skills: %s (%d of %d required)",
+			strings.Join(rec.MatchedSkills, ", "), len(rec.MatchedSkills), len(required)))
+	}
+	if best != nil && bestMatches > 0 {
+		rec.Reasons = append(rec.Reasons, fmt.Sprintf("Open position that fits you: %s", best.Role))
+	}
+
+	// Compare with the level of the best position, falling back to the project's
+	wantLevel := project.Level
+	if best != nil && best.Level != "" {
+		wantLevel = best.Level
+	}
+	haveLevel := averageLevel(matchedLevels)
+	if haveLevel == "" {
+		haveLevel = averageLevel(levelsOf(userSkills))
+	}
+	levelScore := levelFit(haveLevel, wantLevel)
+	switch {
+	case haveLevel == "" || wantLevel == "":
+	case levelScore == 1:
+		rec.Reasons = append(rec.Reasons, fmt.Sprintf("Looking for %s level, same as yours", wantLevel))
+	case levelScore > 0:
+		rec.Reasons = append(rec.Reasons, fmt.Sprintf("Looking for %s level, close to yours", wantLevel))
+	}
+
+	age := now.Sub(project.CreatedAt)
+	recencyScore := math.Pow(0.5, age.Hours()/recencyHalfLife.Hours())
+	if recencyScore >= 0.5 {
+		rec.Reasons = append(rec.Reasons, fmt.Sprintf("Created %s", describeAge(age)))
+	}
+
+	seatScore := 0.5 // unknown capacity for projects without positions
+	if len(project.Positions) > 0 {
+		seatScore = math.Min(float64(freeSeats), seatsForFullScore) / seatsForFullScore
+		if freeSeats == 1 {
+			rec.Reasons = append(rec.Reasons, "1 seat still open")
+		} else {
+			rec.Reasons = append(rec.Reasons, fmt.Sprintf("%d seats still open", freeSeats))
+		}
+	}
+
+	score := weightSkills*skillScore + weightLevel*levelScore + weightRecency*recencyScore + weightSeats*seatScore
+	rec.Score = math.Round(score*1000) / 1000
+
+	return rec, true
+}
+
+// userSkillIndex keys a user's skills by normalized name.
+func userSkillIndex(userSkills []models.UserSkill) map[string]models.UserSkill {
+	index := make(map[string]models.UserSkill, len(userSkills))
+	for _, userSkill := range userSkills {
+		index[skills.Normalize(userSkill.Skill.Name)] = userSkill
+	}
+	return index
+}
+
+func levelsOf(userSkills map[string]models.UserSkill) []string {
+	levels := make([]string, 0, len(userSkills))
+	for _, userSkill := range userSkills {
+		levels = append(levels, userSkill.Level)
+	}
+	return levels
+}
+
+// levelRank orders levels from 1 (beginner) to 3 (expert); unknown is 0.
+func levelRank(level string) int {
+	switch level {
+	case models.LevelBeginner:
+		return 1
+	case models.LevelMiddle:
+		return 2
+	case models.LevelExpert:
+		return 3
+	}
+	return 0
+}
+
+// averageLevel returns the level closest to the mean of the known levels, or
+// "" when none is known.
+func averageLevel(levels []string) string {
+	sum, n := 0, 0
+	for _, level := range levels {
+		if rank := levelRank(level); rank > 0 {
+			sum += rank
+			n++
+		}
+	}
+	if n == 0 {
+		return ""
+	}
+	return []string{models.LevelBeginner, models.LevelMiddle, models.LevelExpert}[int(math.Round(float64(sum)/float64(n)))-1]
+}
+
+// levelFit scores how well a level matches the wanted one: 1 for the same
+// level, 0.5 for a neighbouring one and 0 otherwise. Unknown levels score 0.5.
+func levelFit(have, want string) float64 {
+	h, w := levelRank(have), levelRank(want)
+	if h == 0 || w == 0 {
+		return 0.5
+	}
+	switch diff := h - w; diff {
+	case 0:
+		return 1
+	case -1, 1:
+		return 0.5
+	}
+	return 0
+}
+
+func describeAge(age time.Duration) string {
+	switch days := int(age.Hours() / 24); days {
+	case 0:
+		return "today"
+	case 1:
+		return "yesterday"
+	default:
+		return fmt.Sprintf("%d days ago", days)
+	}
+}
+
+func derefInt64(v *int64) int64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"project-exchange/internal/database"
+	"project-exchange/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UpdateMemberRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// @Summary Change member role
+// @Description Change the role of an accepted member (only project owner). Maintainers review applications, invite and remove members, edit the project and moderate chat; members chat; viewers can only read
+// @Tags members
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Project ID"
+// @Param userId path int true "User ID"
+// @Param role body UpdateMemberRoleRequest true "maintainer, member or viewer"
+// @Success 200 {object} models.ProjectMember
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /projects/{id}/members/{userId}/role [put]
+func UpdateMemberRole(c *gin.Context) {
+	project, ok := authorizeProject(c, models.PermManageRoles, "change member roles")
+	if !ok {
+		return
+	}
+
+	memberUserID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req UpdateMemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !models.IsMemberRole(req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role must be maintainer, member or viewer"})
+		return
+	}
+
+	var member models.ProjectMember
+	err = database.GetDB().
+		Where("project_id = ? AND user_id = ? AND status = ?", project.ID, memberUserID, models.MemberStatusAccepted).
+		First(&member).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+
+	if err := database.GetDB().Model(&member).Update("role", req.Role).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role"})
+		return
+	}
+
+	database.GetDB().Preload("User").Preload("Position").First(&member, member.ID)
+
+	c.JSON(http.StatusOK, member)
+}
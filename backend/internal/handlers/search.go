@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"html"
+	"strings"
+	"unicode"
+
+	"project-exchange/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Matches come back from FTS5 wrapped in private-use characters, which are
+// swapped for <mark> tags once the rest of the text has been HTML-escaped.
+const (
+	matchStart = "\uE000"
+	matchEnd   = "\uE001"
+)
+
+// searchSelect ranks hits with title matches weighted above category and
+// description matches. Lower bm25 scores are better.
+const searchSelect = `projects.id AS id,
+	bm25(projects_fts, 10.0, 1.0, 4.0) AS rank,
+	highlight(projects_fts, 0, '` + matchStart + `', '` + matchEnd + `') AS title,
+	snippet(projects_fts, 1, '` + matchStart + `', '` + matchEnd + `', '…', 16) AS snippet`
+
+var markReplacer = strings.NewReplacer(matchStart, "<mark>", matchEnd, "</mark>")
+
+// markMatches HTML-escapes user text from a search hit and marks its matches,
+// so clients can render the result as HTML.
+func markMatches(text string) string {
+	return markReplacer.Replace(html.EscapeString(text))
+}
+
+type searchHit struct {
+	ID      uint
+	Rank    float64
+	Title   string
+	Snippet string
+}
+
+// buildMatchQuery turns free text into an FTS5 query that requires every word
+// as a prefix. Each word is quoted so user input cannot inject FTS syntax.
+func buildMatchQuery(q string) string {
+	words := strings.FieldsFunc(q, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, `"`+word+`"*`)
+	}
+	return strings.Join(terms, " ")
+}
+
+func hitKey(hit searchHit) (float64, uint) { return hit.Rank, hit.ID }
+
+// searchProjects restricts query to projects matching the full-text query and
+// selects searchHit rows. Ranking functions only work directly on the FTS
+// table, so callers page over the result as a subquery.
+func searchProjects(query *gorm.DB, match string) *gorm.DB {
+	return query.
+		Joins("JOIN projects_fts ON projects_fts.rowid = projects.id").
+		Where("projects_fts MATCH ?", match).
+		Select(searchSelect)
+}
+
+// loadSearchResults loads the projects behind the hits, keeping hit order.
+func loadSearchResults(db *gorm.DB, hits []searchHit) ([]models.Project, error) {
+	if len(hits) == 0 {
+		return []models.Project{}, nil
+	}
+
+	ids := make([]uint, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+
+	var found []models.Project
+	if err := db.Model(&models.Project{}).
+		Scopes(withMemberCount).
+		Preload("Owner").
+		Where("projects.id IN ?", ids).
+		Find(&found).Error; err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]models.Project, len(found))
+	for _, project := range found {
+		byID[project.ID] = project
+	}
+
+	projects := make([]models.Project, 0, len(hits))
+	for _, hit := range hits {
+		project, ok := byID[hit.ID]
+		if !ok {
+			continue
+		}
+		project.Highlight = &models.ProjectHighlight{
+			Title:   markMatches(hit.Title),
+			Snippet: markMatches(hit.Snippet),
+			Rank:    hit.Rank,
+		}
+		projects = append(projects, project)
+	}
+
+	return projects, nil
+}
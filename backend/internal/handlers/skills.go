@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"project-exchange/internal/database"
+	"project-exchange/internal/models"
+	"project-exchange/internal/skills"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultSkillSuggestions = 10
+	maxSkillSuggestions     = 50
+	maxUserSkills           = 30
+)
+
+type SkillLevelInput struct {
+	Name  string `json:"name" binding:"required"`
+	Level string `json:"level"`
+}
+
+// @Summary Autocomplete skills
+// @Description Suggest skills from the taxonomy by name or alias prefix, most used first
+// @Tags skills
+// @Produce json
+// @Param q query string false "Name or alias prefix"
+// @Param limit query int false "Maximum number of suggestions (default 10, max 50)"
+// @Success 200 {array} models.Skill
+// @Failure 400 {object} map[string]interface{}
+// @Router /skills [get]
+func GetSkills(c *gin.Context) {
+	limit := defaultSkillSuggestions
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxSkillSuggestions {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Limit must be between 1 and %d", maxSkillSuggestions)})
+			return
+		}
+		limit = n
+	}
+
+	db := database.GetDB()
+	query := db.Model(&models.Skill{}).
+		Select("skills.*, (SELECT COUNT(*) FROM user_skills WHERE user_skills.skill_id = skills.id) AS user_count")
+
+	if q := skills.Normalize(c.Query("q")); q != "" {
+		pattern := escapeLike(q) + "%"
+		query = query.Where("skills.slug LIKE ? ESCAPE '\\' OR skills.id IN (?)", pattern,
+			db.Model(&models.SkillAlias{}).Select("skill_id").Where("alias LIKE ? ESCAPE '\\'", pattern))
+	}
+
+	var result []models.Skill
+	if err := query.Order("user_count DESC").Order("skills.name").Limit(limit).Find(&result).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch skills"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// replaceUserSkills swaps a user's skills for the given list and refreshes
+// the legacy comma-separated Skills column to match.
+func replaceUserSkills(tx *gorm.DB, user *models.User, inputs []SkillLevelInput) error {
+	if err := tx.Where("user_id = ?", user.ID).Delete(&models.UserSkill{}).Error; err != nil {
+		return err
+	}
+
+	user.SkillLevels = nil
+	names := make([]string, 0, len(inputs))
+	for _, input := range inputs {
+		skill, err := skills.Resolve(tx, input.Name)
+		if err != nil {
+			return err
+		}
+		if containsString(names, skill.Name) {
+			continue
+		}
+
+		userSkill := models.UserSkill{UserID: user.ID, SkillID: skill.ID, Skill: skill, Level: input.Level}
+		if err := tx.Omit("Skill").Create(&userSkill).Error; err != nil {
+			return err
+		}
+		user.SkillLevels = append(user.SkillLevels, userSkill)
+		names = append(names, skill.Name)
+	}
+
+	user.Skills = strings.Join(names, ", ")
+	return tx.Model(user).Update("skills", user.Skills).Error
+}
+
+// validateSkillLevels checks that every skill has a name and a known level.
+func validateSkillLevels(inputs []SkillLevelInput) error {
+	names := make([]string, 0, len(inputs))
+	for _, input := range inputs {
+		if input.Level != "" && !models.IsLevel(input.Level) {
+			return fmt.Errorf("Skill %q: level must be beginner, middle or expert", input.Name)
+		}
+		names = append(names, input.Name)
+	}
+	return validateSkillNames(names)
+}
+
+// validateSkillNames limits how many skills a user may list and how long
+// their names may be, since unknown names are added to the shared taxonomy.
+func validateSkillNames(names []string) error {
+	if len(names) > maxUserSkills {
+		return fmt.Errorf("At most %d skills are allowed", maxUserSkills)
+	}
+	for _, name := range names {
+		name = skills.Clean(name)
+		if name == "" {
+			return fmt.Errorf("Skill name is required")
+		}
+		if utf8.RuneCountInString(name) > skills.MaxNameLength {
+			return fmt.Errorf("Skill names must be at most %d characters", skills.MaxNameLength)
+		}
+	}
+	return nil
+}
+
+// escapeLike escapes LIKE wildcards in user input.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// skillLevelsFromString turns a legacy comma-separated skills string into
+// structured input, carrying over the levels of skills the user already has.
+func skillLevelsFromString(db *gorm.DB, raw string, current []models.UserSkill) []SkillLevelInput {
+	levels := make(map[string]string, len(current))
+	for _, userSkill := range current {
+		levels[userSkill.Skill.Name] = userSkill.Level
+	}
+
+	names := skills.ParseList(raw)
+	inputs := make([]SkillLevelInput, 0, len(names))
+	for _, name := range names {
+		inputs = append(inputs, SkillLevelInput{Name: name, Level: levels[skills.Canonical(db, name)]})
+	}
+	return inputs
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"project-exchange/internal/config"
+	"project-exchange/internal/database"
+	"project-exchange/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var errRefreshTokenReused = errors.New("refresh token reuse detected")
+
+func accessTokenTTL() time.Duration {
+	return config.Duration("ACCESS_TOKEN_TTL", 15*time.Minute)
+}
+
+func refreshTokenTTL() time.Duration {
+	return config.Duration("REFRESH_TOKEN_TTL", 30*24*time.Hour)
+}
+
+// newRandomToken returns a URL-safe random string suitable for bearer secrets.
+func newRandomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashToken returns the value stored in the database for a bearer secret, so
+// a database leak does not hand out usable tokens.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// issueTokens starts a new session for the user and returns an access token
+// and the first refresh token of the session's family.
+func issueTokens(userID uint) (TokenResponse, error) {
+	familyID, err := newRandomToken()
+	if err != nil {
+		return TokenResponse{}, err
+	}
+	return issueTokensInFamily(database.GetDB(), userID, familyID)
+}
+
+func issueTokensInFamily(tx *gorm.DB, userID uint, familyID string) (TokenResponse, error) {
+	refreshToken, err := newRandomToken()
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	record := models.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: time.Now().Add(refreshTokenTTL()),
+	}
+	if err := tx.Create(&record).Error; err != nil {
+		return TokenResponse{}, err
+	}
+
+	accessToken, err := generateToken(userID, familyID)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	return TokenResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(accessTokenTTL().Seconds()),
+	}, nil
+}
+
+// rotateRefreshToken exchanges a refresh token for a new token pair in the same
+// family. Presenting a token that was already used revokes the whole family.
+func rotateRefreshToken(refreshToken string) (TokenResponse, error) {
+	var result TokenResponse
+	reused := false
+
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		var record models.RefreshToken
+		if err := tx.Where("token_hash = ?", hashToken(refreshToken)).First(&record).Error; err != nil {
+			return err
+		}
+
+		if record.RevokedAt != nil || time.Now().After(record.ExpiresAt) {
+			return gorm.ErrRecordNotFound
+		}
+
+		// Mark the token used only if nobody else has; losing the race is a replay.
+		res := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL", record.ID).
+			Update("used_at", time.Now())
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			reused = true
+			return revokeTokenFamily(tx, record.FamilyID)
+		}
+
+		var err error
+		result, err = issueTokensInFamily(tx, record.UserID, record.FamilyID)
+		return err
+	})
+	if err != nil {
+		return TokenResponse{}, err
+	}
+	if reused {
+		return TokenResponse{}, errRefreshTokenReused
+	}
+
+	return result, nil
+}
+
+func revokeTokenFamily(tx *gorm.DB, familyID string) error {
+	return tx.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// revokeUserTokens ends every session the user has open.
+func revokeUserTokens(tx *gorm.DB, userID uint) error {
+	return tx.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"project-exchange/internal/database"
+	"project-exchange/internal/jobs"
+	"project-exchange/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// deletedSorts order deleted projects by when they were deleted. The cursor
+// only holds the id; the deletion time is looked up from it.
+var deletedSorts = map[string]sortOption{
+	"newest": {Key: deletedAtSQL, KeyOfID: deletedAtOfIDSQL, Desc: true},
+	"oldest": {Key: deletedAtSQL, KeyOfID: deletedAtOfIDSQL},
+}
+
+const (
+	deletedAtSQL     = "julianday(projects.deleted_at)"
+	deletedAtOfIDSQL = "(SELECT julianday(deleted_at) FROM projects WHERE id = ?)"
+)
+
+func deletedProjectKey(p DeletedProject) (float64, uint) { return 0, p.ID }
+
+// DeletedProject is a soft-deleted project that can still be restored until
+// PurgeAt.
+type DeletedProject struct {
+	models.Project
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+// @Summary List deleted projects
+// @Description List your deleted projects that can still be restored, with the time each one is purged for good
+// @Tags projects
+// @Security BearerAuth
+// @Produce json
+// @Param sort query string false "newest (default) or oldest, by deletion time"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param total query bool false "Include the total count"
+// @Success 200 {object} Page[DeletedProject]
+// @Failure 400 {object} map[string]interface{}
+// @Router /me/deleted-projects [get]
+func GetDeletedProjects(c *gin.Context) {
+	params, err := parsePageParams(c, deletedSorts, "newest")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	retention := jobs.Retention()
+	query := database.GetDB().Unscoped().Model(&models.Project{}).
+		Where("owner_id = ? AND deleted_at IS NOT NULL AND deleted_at > ?", c.GetUint("user_id"), time.Now().Add(-retention))
+
+	total, err := params.count(query.Session(&gorm.Session{}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deleted projects"})
+		return
+	}
+
+	var projects []models.Project
+	if err := params.apply(query, "projects.id").Scopes(withMemberCount).Preload("Owner").Find(&projects).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deleted projects"})
+		return
+	}
+
+	items := make([]DeletedProject, len(projects))
+	for i, p := range projects {
+		items[i] = DeletedProject{
+			Project:   p,
+			DeletedAt: p.DeletedAt.Time,
+			PurgeAt:   p.DeletedAt.Time.Add(retention),
+		}
+	}
+
+	c.JSON(http.StatusOK, newPage(items, params, total, deletedProjectKey))
+}
+
+// @Summary Restore project
+// @Description Restore a deleted project before it is purged (only project owner)
+// @Tags projects
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Project ID"
+// @Success 200 {object} models.Project
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 410 {object} map[string]interface{}
+// @Router /projects/{id}/restore [post]
+func RestoreProject(c *gin.Context) {
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	var project models.Project
+	if err := database.GetDB().Unscoped().First(&project, projectID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+
+	if !can(project, c.GetUint("user_id"), models.PermDeleteProject) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only project owner can restore the project"})
+		return
+	}
+
+	if !project.DeletedAt.Valid {
+		c.JSON(http.StatusConflict, gin.H{"error": "Project is not deleted"})
+		return
+	}
+
+	cutoff := time.Now().Add(-jobs.Retention())
+	if project.DeletedAt.Time.Before(cutoff) {
+		c.JSON(http.StatusGone, gin.H{"error": "Project can no longer be restored"})
+		return
+	}
+
+	// The purger may be running; only restore what it hasn't taken yet
+	res := database.GetDB().Unscoped().Model(&models.Project{}).
+		Where("id = ? AND deleted_at IS NOT NULL AND deleted_at >= ?", project.ID, cutoff).
+		Update("deleted_at", nil)
+	if res.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore project"})
+		return
+	}
+	if res.RowsAffected == 0 {
+		c.JSON(http.StatusGone, gin.H{"error": "Project can no longer be restored"})
+		return
+	}
+
+	database.GetDB().Scopes(withMemberCount).Preload("Owner").First(&project, project.ID)
+
+	c.JSON(http.StatusOK, project)
+}
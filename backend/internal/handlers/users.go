@@ -3,17 +3,117 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"project-exchange/internal/database"
 	"project-exchange/internal/models"
+	"project-exchange/internal/skills"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type UpdateUserRequest struct {
 	Name   string `json:"name"`
-	Skills string `json:"skills"`
+	// Comma-separated skills; unchanged when omitted along with SkillLevels
+	Skills *string `json:"skills"`
 	Bio    string `json:"bio"`
+	// Open to joining projects; unchanged when omitted
+	Available *bool `json:"available"`
+	// Structured skills; when present they take precedence over Skills
+	SkillLevels []SkillLevelInput `json:"skill_levels"`
+	// Email digest: off, daily or weekly; unchanged when omitted
+	DigestFrequency *string `json:"digest_frequency"`
+}
+
+func userKey(u models.PublicUserResponse) (float64, uint) { return 0, u.ID }
+
+// @Summary Search users
+// @Description Search users by name or skill. Results never include email addresses
+// @Tags users
+// @Produce json
+// @Param q query string false "Name or skill"
+// @Param skill query []string false "Required skill, repeatable; all must match" collectionFormat(multi)
+// @Param level query string false "Skill level (beginner, middle, expert); applies to the given skills, or to any skill"
+// @Param available query bool false "Filter by availability"
+// @Param sort query string false "newest (default) or oldest"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param total query bool false "Include the total count"
+// @Success 200 {object} Page[models.PublicUserResponse]
+// @Failure 400 {object} map[string]interface{}
+// @Router /users [get]
+func GetUsers(c *gin.Context) {
+	params, err := parsePageParams(c, creationSorts, "newest")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := database.GetDB()
+	query := db.Model(&models.User{}).Where("users.is_system = ?", false)
+
+	level := c.Query("level")
+	if level != "" && !models.IsLevel(level) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid level"})
+		return
+	}
+
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		namePattern := "%" + escapeLike(q) + "%"
+		if skill, err := skills.Lookup(db, q); err == nil {
+			query = query.Where("users.name LIKE ? ESCAPE '\\' OR users.id IN (?)", namePattern,
+				db.Model(&models.UserSkill{}).Select("user_id").Where("skill_id = ?", skill.ID))
+		} else {
+			query = query.Where("users.name LIKE ? ESCAPE '\\'", namePattern)
+		}
+	}
+
+	if names := c.QueryArray("skill"); len(names) > 0 {
+		for _, name := range names {
+			skill, err := skills.Lookup(db, name)
+			if err != nil {
+				// Nobody can have a skill the taxonomy doesn't know
+				c.JSON(http.StatusOK, Page[models.PublicUserResponse]{Items: []models.PublicUserResponse{}})
+				return
+			}
+			withSkill := db.Model(&models.UserSkill{}).Select("user_id").Where("skill_id = ?", skill.ID)
+			if level != "" {
+				withSkill = withSkill.Where("level = ?", level)
+			}
+			query = query.Where("users.id IN (?)", withSkill)
+		}
+	} else if level != "" {
+		query = query.Where("users.id IN (?)", db.Model(&models.UserSkill{}).Select("user_id").Where("level = ?", level))
+	}
+
+	if raw := c.Query("available"); raw != "" {
+		available, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid available value"})
+			return
+		}
+		query = query.Where("users.available = ?", available)
+	}
+
+	total, err := params.count(query.Session(&gorm.Session{}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+
+	var users []models.User
+	if err := params.apply(query, "users.id").Preload("SkillLevels.Skill").Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+
+	result := make([]models.PublicUserResponse, 0, len(users))
+	for _, user := range users {
+		result = append(result, user.ToPublicResponse())
+	}
+
+	c.JSON(http.StatusOK, newPage(result, params, total, userKey))
 }
 
 // @Summary Get user profile
@@ -32,25 +132,18 @@ func GetUser(c *gin.Context) {
 	}
 
 	var user models.User
-	if err := database.GetDB().First(&user, userID).Error; err != nil {
+	if err := database.GetDB().Preload("SkillLevels.Skill").First(&user, userID).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
 	}
 
-	userResponse := models.UserResponse{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		Skills:    user.Skills,
-		Bio:       user.Bio,
-		CreatedAt: user.CreatedAt,
-	}
+	userResponse := user.ToResponse()
 
 	c.JSON(http.StatusOK, userResponse)
 }
 
 // @Summary Update user profile
-// @Description Update user profile (only owner can update)
+// @Description Update user profile (only owner can update). Skills may be sent either as structured skill_levels or as a comma-separated skills string
 // @Tags users
 // @Security BearerAuth
 // @Accept json
@@ -87,32 +180,61 @@ func UpdateUser(c *gin.Context) {
 		return
 	}
 
+	if err := validateSkillLevels(req.SkillLevels); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.SkillLevels == nil && req.Skills != nil {
+		if err := validateSkillNames(skills.ParseList(*req.Skills)); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if req.DigestFrequency != nil && !models.IsDigestFrequency(*req.DigestFrequency) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Digest frequency must be off, daily or weekly"})
+		return
+	}
+
 	var user models.User
-	if err := database.GetDB().First(&user, userID).Error; err != nil {
+	if err := database.GetDB().Preload("SkillLevels.Skill").First(&user, userID).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
 	}
 
+	// Legacy clients send a plain string; keep the levels they can't express
+	levels := req.SkillLevels
+	if levels == nil && req.Skills != nil {
+		levels = skillLevelsFromString(database.GetDB(), *req.Skills, user.SkillLevels)
+	}
+
 	// Update fields
 	if req.Name != "" {
 		user.Name = req.Name
 	}
-	user.Skills = req.Skills
 	user.Bio = req.Bio
+	if req.Available != nil {
+		user.Available = *req.Available
+	}
+	if req.DigestFrequency != nil {
+		user.DigestFrequency = *req.DigestFrequency
+	}
 
-	if err := database.GetDB().Save(&user).Error; err != nil {
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("SkillLevels").Save(&user).Error; err != nil {
+			return err
+		}
+		if levels == nil {
+			return nil
+		}
+		return replaceUserSkills(tx, &user, levels)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
 		return
 	}
 
-	userResponse := models.UserResponse{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		Skills:    user.Skills,
-		Bio:       user.Bio,
-		CreatedAt: user.CreatedAt,
-	}
+	userResponse := user.ToResponse()
 
 	c.JSON(http.StatusOK, userResponse)
 }
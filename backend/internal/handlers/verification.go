@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"project-exchange/internal/config"
+	"project-exchange/internal/database"
+	"project-exchange/internal/mailer"
+	"project-exchange/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const purposeVerifyEmail = "verify_email"
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// emailClaims are carried by signed links sent by email. The address is part
+// of the claims so a link stops working once the account's email changes.
+type emailClaims struct {
+	UserID  uint   `json:"user_id"`
+	Email   string `json:"email"`
+	Purpose string `json:"purpose"`
+	jwt.RegisteredClaims
+}
+
+func emailVerificationTTL() time.Duration {
+	return config.Duration("EMAIL_VERIFICATION_TTL", 48*time.Hour)
+}
+
+func signEmailToken(userID uint, email, purpose string, ttl time.Duration) (string, error) {
+	claims := emailClaims{
+		UserID:  userID,
+		Email:   email,
+		Purpose: purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(os.Getenv("SECRET_KEY")))
+}
+
+func parseEmailToken(tokenString, purpose string) (*emailClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &emailClaims{}, func(token *jwt.Token) (interface{}, error) {
+		return []byte(os.Getenv("SECRET_KEY")), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	claims, ok := token.Claims.(*emailClaims)
+	if !ok || claims.Purpose != purpose {
+		return nil, errors.New("invalid token purpose")
+	}
+
+	return claims, nil
+}
+
+// sendVerificationEmail mails a signed verification link for the user's
+// current address.
+func sendVerificationEmail(user models.User) error {
+	token, err := signEmailToken(user.ID, user.Email, purposeVerifyEmail, emailVerificationTTL())
+	if err != nil {
+		return err
+	}
+
+	return mailer.GetMailer().Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Confirm your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below. It expires in %s.\n\n%s\n",
+			user.Name, emailVerificationTTL(), appURL("/verify-email?token="+token)),
+	})
+}
+
+// @Summary Verify email
+// @Description Confirm an email address using the signed token from the verification email
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body VerifyEmailRequest true "Verification token"
+// @Success 200 {object} models.UserResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /auth/verify [post]
+func VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	claims, err := parseEmailToken(req.Token, purposeVerifyEmail)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification token"})
+		return
+	}
+
+	var user models.User
+	if err := database.GetDB().First(&user, claims.UserID).Error; err != nil || user.Email != claims.Email {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification token"})
+		return
+	}
+
+	// Verifying twice is harmless; keep the original timestamp
+	if user.VerifiedAt == nil {
+		now := time.Now()
+		user.VerifiedAt = &now
+		if err := database.GetDB().Model(&user).Update("verified_at", now).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, user.ToResponse())
+}
+
+// @Summary Resend verification email
+// @Description Send a new verification link to the authenticated user's email address
+// @Tags auth
+// @Security BearerAuth
+// @Success 202 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /auth/verify/resend [post]
+func ResendVerification(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var user models.User
+	if err := database.GetDB().First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if user.VerifiedAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email is already verified"})
+		return
+	}
+
+	if err := sendVerificationEmail(user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send verification email"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Verification email sent"})
+}
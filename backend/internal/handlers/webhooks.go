@@ -0,0 +1,390 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"project-exchange/internal/database"
+	"project-exchange/internal/models"
+	"project-exchange/internal/webhooks"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func webhookKey(w models.Webhook) (float64, uint) { return 0, w.ID }
+
+func deliveryKey(d models.WebhookDelivery) (float64, uint) { return 0, d.ID }
+
+type WebhookRequest struct {
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events" binding:"required"`
+	Active *bool    `json:"active"` // default true
+}
+
+// WebhookResponse is returned once when a webhook is created; the secret
+// can't be recovered later.
+type WebhookResponse struct {
+	models.Webhook
+	Secret string `json:"secret"`
+}
+
+// @Summary Create a webhook
+// @Description Register a URL that receives the project's events as JSON (only project owner). Each request carries an X-Webhook-Signature header with the HMAC-SHA256 of the body, keyed by the secret returned here. Events: project.updated, member.applied, member.accepted, message.posted
+// @Tags webhooks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Project ID"
+// @Param webhook body WebhookRequest true "Webhook settings"
+// @Success 201 {object} WebhookResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /projects/{id}/webhooks [post]
+func CreateWebhook(c *gin.Context) {
+	project, ok := authorizeProject(c, models.PermManageWebhooks, "manage webhooks")
+	if !ok {
+		return
+	}
+
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validateWebhook(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	secret, err := newRandomToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+
+	hook := models.Webhook{
+		ProjectID:   project.ID,
+		CreatedByID: c.GetUint("user_id"),
+		URL:         req.URL,
+		Secret:      secret,
+		Events:      req.Events,
+	}
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&hook).Error; err != nil {
+			return err
+		}
+		// Create falls back to the column default for false
+		if req.Active != nil && !*req.Active {
+			return tx.Model(&hook).Update("active", false).Error
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, WebhookResponse{Webhook: hook, Secret: secret})
+}
+
+// @Summary List webhooks
+// @Description List the project's webhooks (only project owner)
+// @Tags webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Project ID"
+// @Param sort query string false "newest (default) or oldest"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param total query bool false "Include the total count"
+// @Success 200 {object} Page[models.Webhook]
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /projects/{id}/webhooks [get]
+func GetWebhooks(c *gin.Context) {
+	project, ok := authorizeProject(c, models.PermManageWebhooks, "manage webhooks")
+	if !ok {
+		return
+	}
+
+	params, err := parsePageParams(c, creationSorts, "newest")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := database.GetDB().Model(&models.Webhook{}).Where("project_id = ?", project.ID)
+
+	total, err := params.count(query.Session(&gorm.Session{}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhooks"})
+		return
+	}
+
+	var hooks []models.Webhook
+	if err := params.apply(query, "id").Find(&hooks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newPage(hooks, params, total, webhookKey))
+}
+
+// @Summary Update a webhook
+// @Description Change a webhook's URL, events or active flag (only project owner). The secret stays the same
+// @Tags webhooks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Project ID"
+// @Param hookId path int true "Webhook ID"
+// @Param webhook body WebhookRequest true "Webhook settings"
+// @Success 200 {object} models.Webhook
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /projects/{id}/webhooks/{hookId} [put]
+func UpdateWebhook(c *gin.Context) {
+	hook, ok := loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validateWebhook(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hook.URL = req.URL
+	hook.Events = req.Events
+	if req.Active != nil {
+		hook.Active = *req.Active
+	}
+
+	err := database.GetDB().Model(&hook).Select("url", "events", "active").Updates(&hook).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, hook)
+}
+
+// @Summary Delete a webhook
+// @Description Delete a webhook and its delivery log (only project owner)
+// @Tags webhooks
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param hookId path int true "Webhook ID"
+// @Success 204
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /projects/{id}/webhooks/{hookId} [delete]
+func DeleteWebhook(c *gin.Context) {
+	hook, ok := loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", hook.ID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&hook).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary List webhook deliveries
+// @Description Get the delivery log of a webhook, newest first (only project owner)
+// @Tags webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Project ID"
+// @Param hookId path int true "Webhook ID"
+// @Param status query string false "Filter by status (pending, succeeded, failed)"
+// @Param sort query string false "newest (default) or oldest"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param total query bool false "Include the total count"
+// @Success 200 {object} Page[models.WebhookDelivery]
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /projects/{id}/webhooks/{hookId}/deliveries [get]
+func GetWebhookDeliveries(c *gin.Context) {
+	hook, ok := loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	params, err := parsePageParams(c, creationSorts, "newest")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := database.GetDB().Model(&models.WebhookDelivery{}).Where("webhook_id = ?", hook.ID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	total, err := params.count(query.Session(&gorm.Session{}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deliveries"})
+		return
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := params.apply(query, "id").Find(&deliveries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deliveries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newPage(deliveries, params, total, deliveryKey))
+}
+
+// @Summary Redeliver a webhook delivery
+// @Description Queue the payload of an earlier delivery again as a new delivery (only project owner). Deliveries of removed messages are not sent again
+// @Tags webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Project ID"
+// @Param hookId path int true "Webhook ID"
+// @Param deliveryId path int true "Delivery ID"
+// @Success 202 {object} models.WebhookDelivery
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /projects/{id}/webhooks/{hookId}/deliveries/{deliveryId}/redeliver [post]
+func RedeliverWebhook(c *gin.Context) {
+	hook, ok := loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	deliveryID, err := strconv.ParseUint(c.Param("deliveryId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery ID"})
+		return
+	}
+
+	var original models.WebhookDelivery
+	if err := database.GetDB().Where("id = ? AND webhook_id = ?", deliveryID, hook.ID).First(&original).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+		return
+	}
+
+	if original.Event == models.WebhookEventMessagePosted {
+		removed, err := webhooks.MessageRemoved(database.GetDB(), original)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue delivery"})
+			return
+		}
+		if removed {
+			c.JSON(http.StatusConflict, gin.H{"error": "Message has been removed"})
+			return
+		}
+	}
+
+	now := time.Now()
+	delivery := models.WebhookDelivery{
+		WebhookID:     hook.ID,
+		Event:         original.Event,
+		Payload:       original.Payload,
+		Status:        models.DeliveryStatusPending,
+		NextAttemptAt: &now,
+	}
+	if err := database.GetDB().Create(&delivery).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue delivery"})
+		return
+	}
+
+	webhooks.GetDispatcher().Wake()
+
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// loadWebhook authorizes the owner and loads the webhook from the :hookId
+// parameter, writing an error response if either fails.
+func loadWebhook(c *gin.Context) (models.Webhook, bool) {
+	var hook models.Webhook
+
+	project, ok := authorizeProject(c, models.PermManageWebhooks, "manage webhooks")
+	if !ok {
+		return hook, false
+	}
+
+	hookID, err := strconv.ParseUint(c.Param("hookId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return hook, false
+	}
+
+	if err := database.GetDB().Where("id = ? AND project_id = ?", hookID, project.ID).First(&hook).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return hook, false
+	}
+
+	return hook, true
+}
+
+// validateWebhook checks the URL and events of a webhook request and drops
+// duplicate events.
+func validateWebhook(req *WebhookRequest) error {
+	u, err := url.Parse(strings.TrimSpace(req.URL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("URL must be an absolute http or https URL")
+	}
+	if err := webhooks.CheckHost(u.Hostname()); err != nil {
+		return err
+	}
+	req.URL = u.String()
+
+	if len(req.Events) == 0 {
+		return errors.New("Subscribe to at least one event")
+	}
+	var events []string
+	for _, event := range req.Events {
+		if !models.IsWebhookEvent(event) {
+			return errors.New("Unknown event: " + event)
+		}
+		if !slices.Contains(events, event) {
+			events = append(events, event)
+		}
+	}
+	req.Events = events
+
+	return nil
+}
+
+// enqueueMemberEvent queues a membership event for the project's webhooks
+// with the member and their public profile in the payload.
+func enqueueMemberEvent(tx *gorm.DB, member models.ProjectMember, event string) error {
+	if err := tx.Preload("User").Preload("Position").First(&member, member.ID).Error; err != nil {
+		return err
+	}
+	return webhooks.Enqueue(tx, member.ProjectID, event, webhooks.NewMemberData(member))
+}
@@ -0,0 +1,179 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"project-exchange/internal/config"
+	"project-exchange/internal/database"
+	"project-exchange/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Retention is how long soft-deleted projects and users are kept, and
+// projects can be restored, before the purger removes them for good.
+func Retention() time.Duration {
+	return config.Duration("DELETED_RETENTION", 30*24*time.Hour)
+}
+
+// StartPurger purges expired soft-deleted rows now and then every
+// PURGE_INTERVAL in the background.
+func StartPurger(db *gorm.DB) {
+	interval := config.Duration("PURGE_INTERVAL", time.Hour)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := Purge(db, time.Now().Add(-Retention())); err != nil {
+				log.Println("Failed to purge deleted rows:", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// Purge hard-deletes projects and users that were soft-deleted before cutoff,
+// together with the rows that belong to them. Each project and user is
+// removed in its own transaction.
+func Purge(db *gorm.DB, cutoff time.Time) error {
+	var projectIDs []uint
+	err := db.Unscoped().Model(&models.Project{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Pluck("id", &projectIDs).Error
+	if err != nil {
+		return err
+	}
+
+	for _, id := range projectIDs {
+		if err := db.Transaction(func(tx *gorm.DB) error { return purgeProject(tx, id) }); err != nil {
+			return err
+		}
+	}
+
+	var userIDs []uint
+	err = db.Unscoped().Model(&models.User{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Pluck("id", &userIDs).Error
+	if err != nil {
+		return err
+	}
+
+	for _, id := range userIDs {
+		if err := db.Transaction(func(tx *gorm.DB) error { return purgeUser(tx, id) }); err != nil {
+			return err
+		}
+	}
+
+	if len(projectIDs) > 0 || len(userIDs) > 0 {
+		log.Printf("Purged %d deleted projects and %d deleted users", len(projectIDs), len(userIDs))
+	}
+	return nil
+}
+
+// purgeProject removes a project and everything attached to it.
+func purgeProject(tx *gorm.DB, projectID uint) error {
+	memberIDs := tx.Model(&models.ProjectMember{}).Select("id").Where("project_id = ?", projectID)
+	hookIDs := tx.Model(&models.Webhook{}).Select("id").Where("project_id = ?", projectID)
+	messageIDs := tx.Model(&models.Message{}).Select("id").Where("project_id = ?", projectID)
+
+	steps := []struct {
+		model interface{}
+		query string
+		args  []interface{}
+	}{
+		{&models.ApplicationAnswer{}, "project_member_id IN (?)", []interface{}{memberIDs}},
+		{&models.MemberStatusChange{}, "project_id = ?", []interface{}{projectID}},
+		{&models.ProjectMember{}, "project_id = ?", []interface{}{projectID}},
+		{&models.MessageEdit{}, "message_id IN (?)", []interface{}{messageIDs}},
+		{&models.MessageReaction{}, "message_id IN (?)", []interface{}{messageIDs}},
+		{&models.Message{}, "project_id = ?", []interface{}{projectID}},
+		{&models.ProjectEvent{}, "project_id = ?", []interface{}{projectID}},
+		{&models.ProjectQuestion{}, "project_id = ?", []interface{}{projectID}},
+		{&models.ProjectPosition{}, "project_id = ?", []interface{}{projectID}},
+		{&models.Invitation{}, "project_id = ?", []interface{}{projectID}},
+		{&models.InviteLink{}, "project_id = ?", []interface{}{projectID}},
+		{&models.OwnershipTransfer{}, "project_id = ?", []interface{}{projectID}},
+		{&models.Notification{}, "project_id = ?", []interface{}{projectID}},
+		{&models.NotificationMute{}, "project_id = ?", []interface{}{projectID}},
+		{&models.WebhookDelivery{}, "webhook_id IN (?)", []interface{}{hookIDs}},
+		{&models.Webhook{}, "project_id = ?", []interface{}{projectID}},
+		{&models.OutboxEvent{}, "project_id = ?", []interface{}{projectID}},
+	}
+	for _, step := range steps {
+		if err := tx.Where(step.query, step.args...).Delete(step.model).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Unscoped().Delete(&models.Project{}, projectID).Error
+}
+
+// purgeUser removes a user, the projects they own and their personal data.
+// Memberships elsewhere are dropped and the seats they held freed up, while
+// their messages and history entries are kept under the placeholder account.
+func purgeUser(tx *gorm.DB, userID uint) error {
+	var ownedIDs []uint
+	if err := tx.Unscoped().Model(&models.Project{}).Where("owner_id = ?", userID).Pluck("id", &ownedIDs).Error; err != nil {
+		return err
+	}
+	for _, id := range ownedIDs {
+		if err := purgeProject(tx, id); err != nil {
+			return err
+		}
+	}
+
+	// Messages in other projects stay, credited to the placeholder account
+	if err := database.ReassignToDeletedUser(tx, userID); err != nil {
+		return err
+	}
+
+	var positionIDs []uint
+	err := tx.Model(&models.ProjectMember{}).
+		Where("user_id = ? AND position_id IS NOT NULL", userID).
+		Pluck("position_id", &positionIDs).Error
+	if err != nil {
+		return err
+	}
+
+	memberIDs := tx.Model(&models.ProjectMember{}).Select("id").Where("user_id = ?", userID)
+
+	steps := []struct {
+		model interface{}
+		query string
+		args  []interface{}
+	}{
+		{&models.ApplicationAnswer{}, "project_member_id IN (?)", []interface{}{memberIDs}},
+		{&models.MemberStatusChange{}, "project_member_id IN (?)", []interface{}{memberIDs}},
+		{&models.ProjectMember{}, "user_id = ?", []interface{}{userID}},
+		{&models.Invitation{}, "invitee_id = ? OR inviter_id = ?", []interface{}{userID, userID}},
+		{&models.OwnershipTransfer{}, "from_user_id = ? OR to_user_id = ?", []interface{}{userID, userID}},
+		{&models.UserSkill{}, "user_id = ?", []interface{}{userID}},
+		{&models.MessageReaction{}, "user_id = ?", []interface{}{userID}},
+		{&models.RefreshToken{}, "user_id = ?", []interface{}{userID}},
+		{&models.PasswordReset{}, "user_id = ?", []interface{}{userID}},
+		{&models.Notification{}, "user_id = ?", []interface{}{userID}},
+		{&models.NotificationMute{}, "user_id = ?", []interface{}{userID}},
+		{&models.OutboxEvent{}, "actor_id = ?", []interface{}{userID}},
+	}
+	for _, step := range steps {
+		if err := tx.Where(step.query, step.args...).Delete(step.model).Error; err != nil {
+			return err
+		}
+	}
+
+	// Seats held by the user are free again
+	if len(positionIDs) > 0 {
+		err := tx.Exec(`UPDATE project_positions SET filled = (
+			SELECT COUNT(*) FROM project_members
+			WHERE project_members.position_id = project_positions.id AND project_members.status = ?
+		) >= seats WHERE id IN ?`, models.MemberStatusAccepted, positionIDs).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Unscoped().Delete(&models.User{}, userID).Error
+}
@@ -0,0 +1,50 @@
+package mailer
+
+import (
+	"log"
+
+	"project-exchange/internal/config"
+)
+
+// Message is a plain-text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers transactional email.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+var defaultMailer Mailer
+
+// InitMailer configures the process-wide mailer from the environment.
+// MAIL_DRIVER selects "smtp" or "outbox" (the default), which writes messages
+// to MAIL_OUTBOX_DIR instead of sending them.
+func InitMailer() {
+	switch driver := config.String("MAIL_DRIVER", "outbox"); driver {
+	case "smtp":
+		defaultMailer = &SMTPMailer{
+			Host:     config.String("SMTP_HOST", "localhost"),
+			Port:     config.Int("SMTP_PORT", 587),
+			Username: config.String("SMTP_USERNAME", ""),
+			Password: config.String("SMTP_PASSWORD", ""),
+			From:     config.String("MAIL_FROM", "no-reply@project-exchange.local"),
+		}
+	case "outbox":
+		defaultMailer = &OutboxMailer{
+			Dir:  config.String("MAIL_OUTBOX_DIR", "./outbox"),
+			From: config.String("MAIL_FROM", "no-reply@project-exchange.local"),
+		}
+	default:
+		log.Fatalf("Unknown MAIL_DRIVER %q", driver)
+	}
+
+	log.Printf("Mailer initialized (%s)", config.String("MAIL_DRIVER", "outbox"))
+}
+
+func GetMailer() Mailer {
+	return defaultMailer
+}
@@ -0,0 +1,51 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// OutboxMailer drops every message into Dir as an .eml file and logs it, so
+// mail flows can be exercised locally without an SMTP server.
+type OutboxMailer struct {
+	Dir  string
+	From string
+
+	seq uint64
+}
+
+func (m *OutboxMailer) Send(msg Message) error {
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%04d-%s.eml",
+		time.Now().UTC().Format("20060102T150405.000000000"),
+		atomic.AddUint64(&m.seq, 1)%10000,
+		sanitizeFileName(msg.To),
+	)
+	path := filepath.Join(m.Dir, name)
+
+	if err := os.WriteFile(path, formatMessage(m.From, msg), 0o600); err != nil {
+		return err
+	}
+
+	log.Printf("Mail to %s (%q) written to %s", msg.To, msg.Subject, path)
+	return nil
+}
+
+func sanitizeFileName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_', r == '@':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
@@ -1,19 +1,43 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"os"
 	"strings"
+	"time"
+
+	"project-exchange/internal/config"
+	"project-exchange/internal/database"
+	"project-exchange/internal/models"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
 type Claims struct {
-	UserID uint `json:"user_id"`
+	UserID    uint   `json:"user_id"`
+	SessionID string `json:"sid"`
 	jwt.RegisteredClaims
 }
 
+// ParseToken validates a signed access token and returns its claims.
+func ParseToken(tokenString string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		return []byte(os.Getenv("SECRET_KEY")), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	claims, ok := token.Claims.(*Claims)
+	if !ok {
+		return nil, errors.New("invalid token claims")
+	}
+
+	return claims, nil
+}
+
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -30,24 +54,139 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-			return []byte(os.Getenv("SECRET_KEY")), nil
-		})
-
-		if err != nil || !token.Valid {
+		claims, err := ParseToken(tokenString)
+		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			c.Abort()
 			return
 		}
 
-		claims, ok := token.Claims.(*Claims)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+		// Reject tokens whose session was logged out or revoked
+		if !sessionActive(claims.SessionID) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+			c.Abort()
+			return
+		}
+
+		// Reject tokens issued before a password or email change
+		if !credentialsCurrent(claims) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Credentials have changed, please log in again"})
 			c.Abort()
 			return
 		}
 
 		c.Set("user_id", claims.UserID)
+		c.Set("session_id", claims.SessionID)
+		c.Next()
+	}
+}
+
+// sessionActive reports whether the refresh token family behind an access
+// token still has at least one unrevoked token.
+func sessionActive(sessionID string) bool {
+	if sessionID == "" {
+		return false
+	}
+
+	var count int64
+	database.GetDB().Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", sessionID).
+		Count(&count)
+	return count > 0
+}
+
+// credentialsCurrent reports whether an access token was issued after its
+// user last changed their password or email address.
+func credentialsCurrent(claims *Claims) bool {
+	var user models.User
+	if err := database.GetDB().Select("id", "credentials_changed_at").First(&user, claims.UserID).Error; err != nil {
+		return false
+	}
+	if user.CredentialsChangedAt == nil {
+		return true
+	}
+	if claims.IssuedAt == nil {
+		return false
+	}
+
+	// iat only has second precision
+	return !claims.IssuedAt.Time.Before(user.CredentialsChangedAt.Truncate(time.Second))
+}
+
+// OptionalAuth identifies the user when a valid bearer token is present but
+// lets anonymous requests through.
+func OptionalAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if tokenString == "" {
+			c.Next()
+			return
+		}
+
+		if claims, err := ParseToken(tokenString); err == nil && sessionActive(claims.SessionID) && credentialsCurrent(claims) {
+			c.Set("user_id", claims.UserID)
+			c.Set("session_id", claims.SessionID)
+		}
+		c.Next()
+	}
+}
+
+// RequireVerifiedEmail blocks users who have not confirmed their email address
+// when REQUIRE_EMAIL_VERIFICATION is enabled. It must run after AuthMiddleware.
+func RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !config.Bool("REQUIRE_EMAIL_VERIFICATION", false) {
+			c.Next()
+			return
+		}
+
+		var user models.User
+		if err := database.GetDB().First(&user, c.GetUint("user_id")).Error; err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+			c.Abort()
+			return
+		}
+
+		if user.VerifiedAt == nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Email address must be verified"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// queryTokenKey holds the token HideQueryToken took off the request URL.
+const queryTokenKey = "query_token"
+
+// WebSocketAuthMiddleware works like AuthMiddleware but also accepts the token
+// from the "token" query parameter, because browsers cannot set headers on
+// WebSocket handshakes. HideQueryToken must run first to take it off the URL.
+func WebSocketAuthMiddleware() gin.HandlerFunc {
+	auth := AuthMiddleware()
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			if token := c.GetString(queryTokenKey); token != "" {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+		auth(c)
+	}
+}
+
+// HideQueryToken removes the "token" query parameter from the request URL so
+// the access log does not record it, keeping it for WebSocketAuthMiddleware.
+// It has to run before the logger.
+func HideQueryToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := c.Request.URL.Query()
+		if _, ok := query["token"]; ok {
+			c.Set(queryTokenKey, query.Get("token"))
+			query.Del("token")
+			c.Request.URL.RawQuery = query.Encode()
+			c.Request.RequestURI = c.Request.URL.RequestURI()
+		}
 		c.Next()
 	}
 }
@@ -57,7 +196,7 @@ func CORS() gin.HandlerFunc {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, PATCH, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
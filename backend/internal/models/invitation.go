@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Invitation statuses.
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusDeclined = "declined"
+	InvitationStatusRevoked  = "revoked"
+)
+
+// Invitation is an owner's invitation of a specific user to join a project.
+type Invitation struct {
+	ID          uint             `gorm:"primaryKey" json:"id"`
+	ProjectID   uint             `gorm:"not null;index" json:"project_id"`
+	Project     *Project         `gorm:"foreignKey:ProjectID" json:"project,omitempty"`
+	InviterID   uint             `gorm:"not null" json:"inviter_id"`
+	Inviter     *User            `gorm:"foreignKey:InviterID" json:"inviter,omitempty"`
+	InviteeID   uint             `gorm:"not null;index" json:"invitee_id"`
+	Invitee     *User            `gorm:"foreignKey:InviteeID" json:"invitee,omitempty"`
+	PositionID  *uint            `json:"position_id"`
+	Position    *ProjectPosition `gorm:"foreignKey:PositionID" json:"position,omitempty"`
+	Message     string           `json:"message,omitempty"`
+	Status      string           `gorm:"not null;default:pending;index" json:"status"` // pending/accepted/declined/revoked
+	RespondedAt *time.Time       `json:"responded_at"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// InviteLink is a shareable link that admits whoever holds it as an accepted
+// member, until it expires, runs out of uses or is revoked. Only the hash of
+// the token is stored.
+type InviteLink struct {
+	ID          uint             `gorm:"primaryKey" json:"id"`
+	ProjectID   uint             `gorm:"not null;index" json:"project_id"`
+	Project     *Project         `gorm:"foreignKey:ProjectID" json:"project,omitempty"`
+	CreatedByID uint             `gorm:"not null" json:"created_by_id"`
+	TokenHash   string           `gorm:"not null;uniqueIndex" json:"-"`
+	PositionID  *uint            `json:"position_id"`
+	Position    *ProjectPosition `gorm:"foreignKey:PositionID" json:"position,omitempty"`
+	MaxUses     int              `gorm:"not null" json:"max_uses"`
+	Uses        int              `gorm:"not null;default:0" json:"uses"`
+	ExpiresAt   time.Time        `json:"expires_at"`
+	RevokedAt   *time.Time       `json:"revoked_at"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+// Usable reports whether the link can still admit members at the given time.
+func (l InviteLink) Usable(now time.Time) bool {
+	return l.RevokedAt == nil && now.Before(l.ExpiresAt) && l.Uses < l.MaxUses
+}
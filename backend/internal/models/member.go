@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// Project member statuses.
+const (
+	MemberStatusPending   = "pending"
+	MemberStatusAccepted  = "accepted"
+	MemberStatusRejected  = "rejected"
+	MemberStatusWithdrawn = "withdrawn"
+	MemberStatusLeft      = "left"
+	MemberStatusRemoved   = "removed"
+)
+
+// memberTransitions lists the statuses a membership may move to from each
+// status. Withdrawn applicants and former members may apply again; rejected
+// and removed users may not.
+var memberTransitions = map[string][]string{
+	MemberStatusPending:   {MemberStatusAccepted, MemberStatusRejected, MemberStatusWithdrawn},
+	MemberStatusAccepted:  {MemberStatusLeft, MemberStatusRemoved},
+	MemberStatusWithdrawn: {MemberStatusPending},
+	MemberStatusLeft:      {MemberStatusPending},
+}
+
+// CanTransitionMember reports whether a membership may move from one status
+// to another.
+func CanTransitionMember(from, to string) bool {
+	for _, next := range memberTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CanJoinByInvite reports whether an invitation may admit a user whose
+// membership is in the given status. Direct invitations are chosen by the
+// owner and also admit previously rejected or removed users; invite links do
+// not.
+func CanJoinByInvite(from string, direct bool) bool {
+	switch from {
+	case MemberStatusPending, MemberStatusWithdrawn, MemberStatusLeft:
+		return true
+	case MemberStatusRejected, MemberStatusRemoved:
+		return direct
+	}
+	return false
+}
+
+// MemberStatusChange records a single status transition of a ProjectMember
+// and who made it. FromStatus is empty for the initial application.
+type MemberStatusChange struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	ProjectMemberID uint      `gorm:"not null;index" json:"project_member_id"`
+	ProjectID       uint      `gorm:"not null;index" json:"project_id"`
+	FromStatus      string    `json:"from_status"`
+	ToStatus        string    `gorm:"not null" json:"to_status"`
+	ActorID         uint      `gorm:"not null" json:"actor_id"`
+	Actor           User      `gorm:"foreignKey:ActorID" json:"actor"`
+	CreatedAt       time.Time `json:"created_at"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// Notification types.
+const (
+	NotificationApplicationReceived = "application_received" // someone applied to a project you review
+	NotificationApplicationAccepted = "application_accepted"
+	NotificationApplicationRejected = "application_rejected"
+	NotificationMessagePosted       = "message_posted"
+	NotificationInvitationReceived  = "invitation_received"
+)
+
+var notificationTypes = []string{
+	NotificationApplicationReceived,
+	NotificationApplicationAccepted,
+	NotificationApplicationRejected,
+	NotificationMessagePosted,
+	NotificationInvitationReceived,
+}
+
+// IsNotificationType reports whether t is a known notification type.
+func IsNotificationType(t string) bool {
+	for _, known := range notificationTypes {
+		if known == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Notification tells a user about something that happened in a project.
+// SubjectID points at the row the event is about: the application for
+// application events, the message or the invitation.
+type Notification struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	Type      string     `gorm:"not null" json:"type"`
+	ProjectID *uint      `json:"project_id"`
+	Project   *Project   `gorm:"foreignKey:ProjectID" json:"project,omitempty"`
+	ActorID   *uint      `json:"actor_id"`
+	Actor     *User      `gorm:"foreignKey:ActorID" json:"actor,omitempty"`
+	SubjectID uint       `json:"subject_id"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// NotificationMute silences notifications for a user. Exactly one of Type and
+// ProjectID is set: a muted type is silenced everywhere, a muted project
+// silences every type for that project.
+type NotificationMute struct {
+	ID        uint      `gorm:"primaryKey" json:"-"`
+	UserID    uint      `gorm:"not null;index" json:"-"`
+	Type      string    `json:"type,omitempty"`
+	ProjectID *uint     `json:"project_id,omitempty"`
+	CreatedAt time.Time `json:"-"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Outbox event types.
+const (
+	OutboxApplicationSubmitted = "application_submitted" // SubjectID is the membership
+	OutboxProjectPublished     = "project_published"     // project became open
+)
+
+// OutboxEvent is a domain change recorded in the same transaction as the
+// change itself, so background jobs such as email digests see exactly the
+// changes that were committed.
+type OutboxEvent struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Type      string    `gorm:"not null;index" json:"type"`
+	ProjectID uint      `gorm:"not null;index" json:"project_id"`
+	ActorID   uint      `gorm:"not null" json:"actor_id"`
+	SubjectID uint      `json:"subject_id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// Digest frequencies.
+const (
+	DigestOff    = "off"
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// IsDigestFrequency reports whether f is a known digest frequency.
+func IsDigestFrequency(f string) bool {
+	return f == DigestOff || f == DigestDaily || f == DigestWeekly
+}
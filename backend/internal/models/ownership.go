@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Ownership transfer statuses.
+const (
+	TransferStatusPending   = "pending"
+	TransferStatusAccepted  = "accepted"
+	TransferStatusDeclined  = "declined"
+	TransferStatusCancelled = "cancelled"
+)
+
+// OwnershipTransfer is the owner's nomination of an accepted member as the
+// new owner. It takes effect once the nominee accepts.
+type OwnershipTransfer struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	ProjectID   uint       `gorm:"not null;index" json:"project_id"`
+	FromUserID  uint       `gorm:"not null" json:"from_user_id"`
+	FromUser    *User      `gorm:"foreignKey:FromUserID" json:"from_user,omitempty"`
+	ToUserID    uint       `gorm:"not null" json:"to_user_id"`
+	ToUser      *User      `gorm:"foreignKey:ToUserID" json:"to_user,omitempty"`
+	Status      string     `gorm:"not null;default:pending;index" json:"status"` // pending/accepted/declined/cancelled
+	RespondedAt *time.Time `json:"responded_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
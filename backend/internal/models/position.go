@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Experience levels used by projects, positions and skills.
+const (
+	LevelBeginner = "beginner"
+	LevelMiddle   = "middle"
+	LevelExpert   = "expert"
+)
+
+// IsLevel reports whether level is a known experience level.
+func IsLevel(level string) bool {
+	return level == LevelBeginner || level == LevelMiddle || level == LevelExpert
+}
+
+// ProjectPosition is a named open role in a project with a number of seats.
+// Filled is kept up to date as members are accepted and leave.
+type ProjectPosition struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	ProjectID uint       `gorm:"not null;index" json:"project_id"`
+	Role      string     `gorm:"not null" json:"role"`
+	Skills    StringList `gorm:"type:text" json:"skills"`
+	Level     string     `json:"level"` // beginner/middle/expert
+	Seats     int        `gorm:"not null;default:1" json:"seats"`
+	Filled    bool       `gorm:"not null;default:false" json:"filled"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+
+	// Accepted members holding this position, only filled in on project details
+	SeatsTaken *int64 `gorm:"->;-:migration" json:"seats_taken,omitempty"`
+}
@@ -13,13 +13,30 @@ type Project struct {
 	Description string `json:"description"`
 	Category    string `json:"category"`
 	Level       string `json:"level"` // beginner/middle/expert
+	Status      string `gorm:"not null;default:open;index" json:"status"` // draft/open/in_progress/completed/archived
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
 	
 	// Relations
-	Members  []ProjectMember `gorm:"foreignKey:ProjectID" json:"members,omitempty"`
-	Messages []Message       `gorm:"foreignKey:ProjectID" json:"messages,omitempty"`
+	Members   []ProjectMember   `gorm:"foreignKey:ProjectID" json:"members,omitempty"`
+	Messages  []Message         `gorm:"foreignKey:ProjectID" json:"messages,omitempty"`
+	Questions []ProjectQuestion `gorm:"foreignKey:ProjectID" json:"questions,omitempty"`
+	Positions []ProjectPosition `gorm:"foreignKey:ProjectID" json:"positions,omitempty"`
+
+	// Accepted member count, filled in by the handlers' member count scope
+	MemberCount int64 `gorm:"->;-:migration" json:"member_count"`
+
+	// Search match details, only set on full-text search results
+	Highlight *ProjectHighlight `gorm:"-" json:"highlight,omitempty"`
+}
+
+// ProjectHighlight holds the matched fragments of a search hit as HTML: the
+// text is escaped and matches are wrapped in <mark> tags.
+type ProjectHighlight struct {
+	Title   string  `json:"title"`
+	Snippet string  `json:"snippet"`
+	Rank    float64 `json:"rank"`
 }
 
 type ProjectMember struct {
@@ -28,9 +45,18 @@ type ProjectMember struct {
 	Project   Project `gorm:"foreignKey:ProjectID" json:"project,omitempty"`
 	UserID    uint   `gorm:"not null" json:"user_id"`
 	User      User   `gorm:"foreignKey:UserID" json:"user"`
-	Status    string `gorm:"default:pending" json:"status"` // pending/accepted/rejected
+	Status    string `gorm:"default:pending" json:"status"` // pending/accepted/rejected/withdrawn/left/removed
+	Role      string `gorm:"not null;default:member" json:"role"` // maintainer/member/viewer
+	// Only shown to reviewers and the applicant, see handlers.Application
+	CoverLetter string `json:"-"`
+	PositionID *uint `gorm:"index" json:"position_id"`
+	Position   *ProjectPosition `gorm:"foreignKey:PositionID" json:"position,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relations
+	History []MemberStatusChange `gorm:"foreignKey:ProjectMemberID" json:"history,omitempty"`
+	Answers []ApplicationAnswer  `gorm:"foreignKey:ProjectMemberID" json:"answers,omitempty"`
 }
 
 type Message struct {
@@ -41,4 +67,48 @@ type Message struct {
 	User      User    `gorm:"foreignKey:UserID" json:"user"`
 	Content   string  `gorm:"not null" json:"content"`
 	CreatedAt time.Time `json:"created_at"`
+
+	// Set when the author last edited the message
+	EditedAt *time.Time `json:"edited_at"`
+
+	// Removed messages stay in the chat as stubs with their content cleared
+	RemovedAt   *time.Time `json:"removed_at"`
+	RemovedByID *uint      `json:"removed_by_id,omitempty"`
+
+	// Replies point at the first message of their thread
+	ReplyToID *uint `gorm:"index" json:"reply_to_id"`
+
+	PinnedAt   *time.Time `json:"pinned_at"`
+	PinnedByID *uint      `json:"pinned_by_id,omitempty"`
+
+	// Filled in by chat listings
+	ReplyCount int64           `gorm:"-" json:"reply_count"`
+	Reactions  []ReactionCount `gorm:"-" json:"reactions,omitempty"`
+}
+
+// MessageReaction is one user's emoji reaction to a message.
+type MessageReaction struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	MessageID uint      `gorm:"not null;uniqueIndex:idx_message_reaction" json:"message_id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_message_reaction;index" json:"user_id"`
+	Emoji     string    `gorm:"not null;uniqueIndex:idx_message_reaction" json:"emoji"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReactionCount is how many people reacted to a message with one emoji.
+type ReactionCount struct {
+	Emoji   string `json:"emoji"`
+	Count   int    `json:"count"`
+	UserIDs []uint `json:"user_ids"`
+}
+
+// MessageRemovedContent replaces the content of a removed message.
+const MessageRemovedContent = "message removed"
+
+// MessageEdit keeps the content a message had before an edit.
+type MessageEdit struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	MessageID uint      `gorm:"not null;index" json:"message_id"`
+	Content   string    `gorm:"not null" json:"content"`
+	CreatedAt time.Time `json:"created_at"` // when the edit replaced this content
 }
@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// Project statuses.
+const (
+	ProjectStatusDraft      = "draft"
+	ProjectStatusOpen       = "open"
+	ProjectStatusInProgress = "in_progress"
+	ProjectStatusCompleted  = "completed"
+	ProjectStatusArchived   = "archived"
+)
+
+// projectTransitions lists the statuses a project may move to from each
+// status. Only open projects accept applications and archived projects are
+// read-only.
+var projectTransitions = map[string][]string{
+	ProjectStatusDraft:      {ProjectStatusOpen, ProjectStatusArchived},
+	ProjectStatusOpen:       {ProjectStatusDraft, ProjectStatusInProgress, ProjectStatusCompleted, ProjectStatusArchived},
+	ProjectStatusInProgress: {ProjectStatusOpen, ProjectStatusCompleted, ProjectStatusArchived},
+	ProjectStatusCompleted:  {ProjectStatusInProgress, ProjectStatusArchived},
+	ProjectStatusArchived:   {ProjectStatusCompleted},
+}
+
+// IsProjectStatus reports whether status is a known project status.
+func IsProjectStatus(status string) bool {
+	_, ok := projectTransitions[status]
+	return ok
+}
+
+// CanTransitionProject reports whether a project may move from one status to
+// another.
+func CanTransitionProject(from, to string) bool {
+	for _, next := range projectTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ProjectAcceptsMembers reports whether members may still be invited into a
+// project in the given status.
+func ProjectAcceptsMembers(status string) bool {
+	return status != ProjectStatusCompleted && status != ProjectStatusArchived
+}
+
+// Project event types.
+const (
+	ProjectEventStatusChanged        = "status_changed"
+	ProjectEventOwnershipRequested   = "ownership_transfer_requested" // From and To are user IDs
+	ProjectEventOwnershipTransferred = "ownership_transferred"        // From and To are user IDs
+)
+
+// ProjectEvent is an entry in a project's history, such as a status change.
+// From and To hold the old and new value of whatever the event changed.
+type ProjectEvent struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ProjectID uint      `gorm:"not null;index" json:"project_id"`
+	ActorID   uint      `gorm:"not null" json:"actor_id"`
+	Actor     User      `gorm:"foreignKey:ActorID" json:"actor"`
+	Type      string    `gorm:"not null" json:"type"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Question types supported in project questionnaires.
+const (
+	QuestionTypeText         = "text"
+	QuestionTypeSingleChoice = "single_choice"
+	QuestionTypeSkillRating  = "skill_rating"
+)
+
+// Skill self-ratings are answered on a 1..5 scale.
+const (
+	SkillRatingMin = 1
+	SkillRatingMax = 5
+)
+
+// ProjectQuestion is one entry of the questionnaire applicants fill in.
+type ProjectQuestion struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	ProjectID uint       `gorm:"not null;index" json:"project_id"`
+	Position  int        `gorm:"not null" json:"position"`
+	Type      string     `gorm:"not null" json:"type"` // text/single_choice/skill_rating
+	Prompt    string     `gorm:"not null" json:"prompt"`
+	Options   StringList `gorm:"type:text" json:"options,omitempty"` // single_choice only
+	Skill     string     `json:"skill,omitempty"`                    // skill_rating only
+	Required  bool       `json:"required"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ApplicationAnswer is an applicant's answer to a questionnaire entry. The
+// prompt is copied so answers stay readable if the questionnaire changes.
+type ApplicationAnswer struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	ProjectMemberID uint      `gorm:"not null;index" json:"project_member_id"`
+	QuestionID      uint      `gorm:"not null" json:"question_id"`
+	Prompt          string    `json:"prompt"`
+	Value           string    `json:"value"`
+	CreatedAt       time.Time `json:"created_at"`
+}
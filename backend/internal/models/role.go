@@ -0,0 +1,71 @@
+package models
+
+// Project roles. The owner role follows from Project.OwnerID and is never
+// stored on a ProjectMember.
+const (
+	RoleOwner      = "owner"
+	RoleMaintainer = "maintainer"
+	RoleMember     = "member"
+	RoleViewer     = "viewer"
+)
+
+// Permission is an action inside a project that depends on the user's role.
+type Permission string
+
+const (
+	PermViewProject        Permission = "view_project" // team-only data: chat, history
+	PermPostMessages       Permission = "post_messages"
+	PermModerateChat       Permission = "moderate_chat"       // remove other people's messages
+	PermReviewApplications Permission = "review_applications" // list, accept and reject applications, find candidates
+	PermInviteMembers      Permission = "invite_members"
+	PermRemoveMembers      Permission = "remove_members"
+	PermEditProject        Permission = "edit_project" // details, questionnaire, positions
+	PermChangeStatus       Permission = "change_status"
+	PermManageRoles        Permission = "manage_roles"
+	PermDeleteProject      Permission = "delete_project"
+	PermTransferOwnership  Permission = "transfer_ownership"
+	PermManageWebhooks     Permission = "manage_webhooks"
+	PermPinMessages        Permission = "pin_messages"
+)
+
+// rolePermissions is the permission matrix of project roles.
+var rolePermissions = map[string][]Permission{
+	RoleOwner: {
+		PermViewProject, PermPostMessages, PermModerateChat, PermReviewApplications, PermInviteMembers,
+		PermRemoveMembers, PermEditProject, PermChangeStatus, PermManageRoles, PermDeleteProject,
+		PermTransferOwnership, PermManageWebhooks, PermPinMessages,
+	},
+	RoleMaintainer: {
+		PermViewProject, PermPostMessages, PermModerateChat, PermReviewApplications, PermInviteMembers,
+		PermRemoveMembers, PermEditProject,
+	},
+	RoleMember: {PermViewProject, PermPostMessages},
+	RoleViewer: {PermViewProject},
+}
+
+// IsMemberRole reports whether role can be assigned to a member.
+func IsMemberRole(role string) bool {
+	return role == RoleMaintainer || role == RoleMember || role == RoleViewer
+}
+
+// MemberRolesWith returns the member roles that grant the permission.
+func MemberRolesWith(perm Permission) []string {
+	var roles []string
+	for _, role := range []string{RoleMaintainer, RoleMember, RoleViewer} {
+		if RoleCan(role, perm) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// RoleCan reports whether a project role grants the permission. The empty
+// role of non-members grants nothing.
+func RoleCan(role string, perm Permission) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == perm {
+			return true
+		}
+	}
+	return false
+}
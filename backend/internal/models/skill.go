@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Skill is a canonical entry of the skills taxonomy. Slug is the normalized
+// form used for lookups.
+type Skill struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"not null" json:"name"`
+	Slug      string    `gorm:"not null;uniqueIndex" json:"slug"`
+	CreatedAt time.Time `json:"-"`
+
+	// Number of users listing the skill, only filled in by autocomplete
+	UserCount *int64 `gorm:"->;-:migration" json:"user_count,omitempty"`
+}
+
+// SkillAlias maps an alternative spelling such as "golang" to a skill.
+type SkillAlias struct {
+	ID      uint   `gorm:"primaryKey" json:"id"`
+	Alias   string `gorm:"not null;uniqueIndex" json:"alias"`
+	SkillID uint   `gorm:"not null;index" json:"skill_id"`
+	Skill   Skill  `gorm:"foreignKey:SkillID" json:"-"`
+}
+
+// UserSkill links a user to a skill with a proficiency level. An empty level
+// means the user did not say.
+type UserSkill struct {
+	ID        uint      `gorm:"primaryKey" json:"-"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_user_skill" json:"-"`
+	SkillID   uint      `gorm:"not null;uniqueIndex:idx_user_skill;index" json:"skill_id"`
+	Skill     Skill     `gorm:"foreignKey:SkillID" json:"skill"`
+	Level     string    `json:"level"` // beginner/middle/expert
+	CreatedAt time.Time `json:"-"`
+}
@@ -1,8 +1,10 @@
 package models
 
 import (
-	"gorm.io/gorm"
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type User struct {
@@ -11,17 +13,88 @@ type User struct {
 	Email        string `gorm:"unique;not null" json:"email"`
 	PasswordHash string `gorm:"not null" json:"-"`
 	Skills       string `json:"skills"`
+	SkillLevels  []UserSkill `gorm:"foreignKey:UserID" json:"skill_levels,omitempty"`
 	Bio          string `json:"bio"`
+	Available    bool   `gorm:"not null;default:true" json:"available"`
+	VerifiedAt   *time.Time `json:"verified_at"`
+	PendingEmail string `json:"-"` // awaiting confirmation of a change; only in UserResponse
+	// CredentialsChangedAt invalidates access tokens issued before it
+	CredentialsChangedAt *time.Time `json:"-"`
+	DigestFrequency string `gorm:"not null;default:off" json:"-"` // off/daily/weekly; only in UserResponse
+	// Set on accounts the application manages itself, such as the deleted user placeholder
+	IsSystem     bool   `gorm:"not null;default:false;index" json:"-"`
+	LastDigestAt *time.Time `json:"-"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
+// ReservedEmailDomain is used for the addresses of system and deleted
+// accounts; nobody can register or switch to an address in it.
+const ReservedEmailDomain = "project-exchange.invalid"
+
+// DeletedUserEmail is the address of the placeholder account that takes over
+// the messages and history of deleted users.
+const DeletedUserEmail = "deleted-user@" + ReservedEmailDomain
+
+// IsReservedEmail reports whether the address belongs to the reserved domain.
+func IsReservedEmail(email string) bool {
+	return strings.HasSuffix(strings.ToLower(strings.TrimSpace(email)), "@"+ReservedEmailDomain)
+}
+
 type UserResponse struct {
-	ID        uint      `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Skills    string    `json:"skills"`
-	Bio       string    `json:"bio"`
-	CreatedAt time.Time `json:"created_at"`
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Email      string     `json:"email"`
+	Skills     string     `json:"skills"`
+	SkillLevels []UserSkill `json:"skill_levels"`
+	Bio        string     `json:"bio"`
+	Available  bool       `json:"available"`
+	VerifiedAt *time.Time `json:"verified_at"`
+	PendingEmail string   `json:"pending_email,omitempty"`
+	DigestFrequency string `json:"digest_frequency"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// PublicUserResponse is the view of a user shown to other users; it leaves
+// out the email address.
+type PublicUserResponse struct {
+	ID          uint        `json:"id"`
+	Name        string      `json:"name"`
+	Skills      string      `json:"skills"`
+	SkillLevels []UserSkill `json:"skill_levels"`
+	Bio         string      `json:"bio"`
+	Available   bool        `json:"available"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// ToResponse converts the user into its public API representation.
+func (u User) ToResponse() UserResponse {
+	return UserResponse{
+		ID:         u.ID,
+		Name:       u.Name,
+		Email:      u.Email,
+		Skills:     u.Skills,
+		SkillLevels: u.SkillLevels,
+		Bio:        u.Bio,
+		Available:  u.Available,
+		VerifiedAt: u.VerifiedAt,
+		PendingEmail: u.PendingEmail,
+		DigestFrequency: u.DigestFrequency,
+		CreatedAt:  u.CreatedAt,
+	}
+}
+
+// ToPublicResponse converts the user into the representation shown to other
+// users.
+func (u User) ToPublicResponse() PublicUserResponse {
+	return PublicUserResponse{
+		ID:          u.ID,
+		Name:        u.Name,
+		Skills:      u.Skills,
+		SkillLevels: u.SkillLevels,
+		Bio:         u.Bio,
+		Available:   u.Available,
+		CreatedAt:   u.CreatedAt,
+	}
 }
@@ -0,0 +1,77 @@
+package models
+
+import "time"
+
+// Webhook event types.
+const (
+	WebhookEventProjectUpdated = "project.updated"
+	WebhookEventMemberApplied  = "member.applied"
+	WebhookEventMemberAccepted = "member.accepted"
+	WebhookEventMessagePosted  = "message.posted"
+)
+
+// WebhookEvents lists every event a webhook can subscribe to.
+var WebhookEvents = []string{
+	WebhookEventProjectUpdated,
+	WebhookEventMemberApplied,
+	WebhookEventMemberAccepted,
+	WebhookEventMessagePosted,
+}
+
+// IsWebhookEvent reports whether event is a known webhook event.
+func IsWebhookEvent(event string) bool {
+	for _, known := range WebhookEvents {
+		if known == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Webhook delivery statuses.
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusFailed    = "failed" // gave up after the last retry
+)
+
+// Webhook is a URL that receives signed JSON payloads for a project's events.
+// The secret signs every payload and is only shown when the hook is created.
+type Webhook struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	ProjectID   uint       `gorm:"not null;index" json:"project_id"`
+	CreatedByID uint       `gorm:"not null" json:"created_by_id"`
+	URL         string     `gorm:"not null" json:"url"`
+	Secret      string     `gorm:"not null" json:"-"`
+	Events      StringList `gorm:"type:text" json:"events"`
+	Active      bool       `gorm:"not null;default:true" json:"active"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Subscribes reports whether the hook wants the event.
+func (w Webhook) Subscribes(event string) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one event queued for a webhook, with the outcome of its
+// latest attempt. Pending deliveries are retried at NextAttemptAt.
+type WebhookDelivery struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	WebhookID      uint       `gorm:"not null;index" json:"webhook_id"`
+	Event          string     `gorm:"not null" json:"event"`
+	Payload        string     `gorm:"type:text;not null" json:"payload"`
+	Status         string     `gorm:"not null;default:pending;index" json:"status"` // pending/succeeded/failed
+	Attempts       int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  *time.Time `gorm:"index" json:"next_attempt_at"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at"`
+	ResponseStatus int        `json:"response_status,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
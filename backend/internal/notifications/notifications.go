@@ -0,0 +1,55 @@
+package notifications
+
+import (
+	"project-exchange/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Notify stores a copy of n for each recipient. The actor is never told about
+// their own action, and recipients who muted n's type or project are skipped.
+// Pass the transaction that makes the change so both commit together.
+func Notify(tx *gorm.DB, n models.Notification, recipients ...uint) error {
+	seen := make(map[uint]bool, len(recipients))
+	var userIDs []uint
+	for _, id := range recipients {
+		if seen[id] || (n.ActorID != nil && *n.ActorID == id) {
+			continue
+		}
+		seen[id] = true
+		userIDs = append(userIDs, id)
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	mutes := tx.Model(&models.NotificationMute{}).Where("user_id IN ?", userIDs)
+	if n.ProjectID != nil {
+		mutes = mutes.Where("type = ? OR project_id = ?", n.Type, *n.ProjectID)
+	} else {
+		mutes = mutes.Where("type = ?", n.Type)
+	}
+	var muted []uint
+	if err := mutes.Distinct().Pluck("user_id", &muted).Error; err != nil {
+		return err
+	}
+	for _, id := range muted {
+		seen[id] = false
+	}
+
+	var rows []models.Notification
+	for _, id := range userIDs {
+		if !seen[id] {
+			continue
+		}
+		row := n
+		row.ID = 0
+		row.UserID = id
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	return tx.Create(&rows).Error
+}
@@ -0,0 +1,185 @@
+package realtime
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	writeWait      = 10 * time.Second
+	pongWait       = 60 * time.Second
+	pingPeriod     = (pongWait * 9) / 10
+	maxMessageSize = 4096
+	sendBufferSize = 32
+)
+
+// Event types pushed to project chat sockets.
+const (
+	EventMessageCreated   = "message.created"
+	EventMessageUpdated   = "message.updated"
+	EventMessageDeleted   = "message.deleted"
+	EventReactionsUpdated = "message.reactions"
+)
+
+// Event is the envelope pushed to every socket subscribed to a project.
+type Event struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// Client is a single WebSocket connection subscribed to one project.
+type Client struct {
+	hub       *Hub
+	conn      *websocket.Conn
+	send      chan []byte
+	projectID uint
+	userID    uint
+}
+
+// Hub keeps track of open project chat sockets and fans events out to them.
+type Hub struct {
+	mu      sync.RWMutex
+	clients map[uint]map[*Client]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{clients: make(map[uint]map[*Client]struct{})}
+}
+
+var defaultHub = NewHub()
+
+func GetHub() *Hub {
+	return defaultHub
+}
+
+// Serve registers the connection with the hub and blocks until it is closed.
+func (h *Hub) Serve(conn *websocket.Conn, projectID, userID uint) {
+	client := &Client{
+		hub:       h,
+		conn:      conn,
+		send:      make(chan []byte, sendBufferSize),
+		projectID: projectID,
+		userID:    userID,
+	}
+
+	h.register(client)
+	go client.writePump()
+	client.readPump()
+}
+
+// Broadcast sends an event to every socket subscribed to the project.
+func (h *Hub) Broadcast(projectID uint, eventType string, data interface{}) {
+	payload, err := json.Marshal(Event{Type: eventType, Data: data})
+	if err != nil {
+		log.Println("Failed to encode realtime event:", err)
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for client := range h.clients[projectID] {
+		select {
+		case client.send <- payload:
+		default:
+			// Slow consumer: drop the connection rather than block everyone else.
+			go h.unregister(client)
+		}
+	}
+}
+
+// DisconnectUser closes every socket the user has open for the project.
+func (h *Hub) DisconnectUser(projectID, userID uint) {
+	h.mu.RLock()
+	var stale []*Client
+	for client := range h.clients[projectID] {
+		if client.userID == userID {
+			stale = append(stale, client)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, client := range stale {
+		h.unregister(client)
+	}
+}
+
+func (h *Hub) register(client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.clients[client.projectID] == nil {
+		h.clients[client.projectID] = make(map[*Client]struct{})
+	}
+	h.clients[client.projectID][client] = struct{}{}
+}
+
+func (h *Hub) unregister(client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	clients, ok := h.clients[client.projectID]
+	if !ok {
+		return
+	}
+	if _, ok := clients[client]; !ok {
+		return
+	}
+
+	delete(clients, client)
+	if len(clients) == 0 {
+		delete(h.clients, client.projectID)
+	}
+	close(client.send)
+}
+
+// readPump only handles control frames; messages are posted over REST.
+func (c *Client) readPump() {
+	defer func() {
+		c.hub.unregister(c)
+		c.conn.Close()
+	}()
+
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		if _, _, err := c.conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+func (c *Client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case payload, ok := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -10,9 +10,10 @@ import (
 )
 
 func SetupRoutes() *gin.Engine {
-	router := gin.Default()
+	router := gin.New()
 
-	// Middleware
+	// Middleware; tokens leave the URL before the logger sees it
+	router.Use(middleware.HideQueryToken(), gin.Logger(), gin.Recovery())
 	router.Use(middleware.CORS())
 
 	// Swagger documentation
@@ -31,31 +32,113 @@ func SetupRoutes() *gin.Engine {
 		{
 			auth.POST("/register", handlers.Register)
 			auth.POST("/login", handlers.Login)
+			auth.POST("/refresh", handlers.Refresh)
+			auth.POST("/logout", handlers.Logout)
+			auth.POST("/password/forgot", handlers.ForgotPassword)
+			auth.POST("/password/reset", handlers.ResetPassword)
+			auth.POST("/verify", handlers.VerifyEmail)
+			auth.POST("/verify/resend", middleware.AuthMiddleware(), handlers.ResendVerification)
+			auth.POST("/email/confirm", handlers.ConfirmEmailChange)
 		}
 
 		// User routes
 		users := api.Group("/users")
 		{
+			users.GET("", handlers.GetUsers)
 			users.GET("/:id", handlers.GetUser)
 			users.PUT("/:id", middleware.AuthMiddleware(), handlers.UpdateUser)
 		}
 
+		// Skills taxonomy
+		api.GET("/skills", handlers.GetSkills)
+
+		// Current user routes
+		me := api.Group("/me", middleware.AuthMiddleware())
+		{
+			me.GET("/applications", handlers.GetMyApplications)
+			me.GET("/recommendations", handlers.GetRecommendations)
+			me.GET("/invitations", handlers.GetMyInvitations)
+			me.GET("/deleted-projects", handlers.GetDeletedProjects)
+			me.GET("/export", handlers.ExportMyData)
+			me.DELETE("", handlers.DeleteMyAccount)
+			me.POST("/password", handlers.ChangePassword)
+			me.POST("/email", handlers.ChangeEmail)
+			me.GET("/notifications", handlers.GetMyNotifications)
+			me.POST("/notifications/read-all", handlers.MarkAllNotificationsRead)
+			me.POST("/notifications/:notificationId/read", handlers.MarkNotificationRead)
+			me.GET("/notifications/preferences", handlers.GetNotificationPreferences)
+			me.PUT("/notifications/preferences", handlers.UpdateNotificationPreferences)
+			me.POST("/invitations/:invitationId/accept", middleware.RequireVerifiedEmail(), handlers.AcceptInvitation)
+			me.POST("/invitations/:invitationId/decline", handlers.DeclineInvitation)
+		}
+
 		// Project routes
 		projects := api.Group("/projects")
 		{
-			projects.GET("", handlers.GetProjects)
-			projects.POST("", middleware.AuthMiddleware(), handlers.CreateProject)
-			projects.GET("/:id", handlers.GetProject)
+			projects.GET("", middleware.OptionalAuth(), handlers.GetProjects)
+			projects.POST("", middleware.AuthMiddleware(), middleware.RequireVerifiedEmail(), handlers.CreateProject)
+			projects.GET("/:id", middleware.OptionalAuth(), handlers.GetProject)
 			projects.PUT("/:id", middleware.AuthMiddleware(), handlers.UpdateProject)
 			projects.DELETE("/:id", middleware.AuthMiddleware(), handlers.DeleteProject)
+			projects.POST("/:id/restore", middleware.AuthMiddleware(), handlers.RestoreProject)
+			projects.POST("/:id/webhooks", middleware.AuthMiddleware(), handlers.CreateWebhook)
+			projects.GET("/:id/webhooks", middleware.AuthMiddleware(), handlers.GetWebhooks)
+			projects.PUT("/:id/webhooks/:hookId", middleware.AuthMiddleware(), handlers.UpdateWebhook)
+			projects.DELETE("/:id/webhooks/:hookId", middleware.AuthMiddleware(), handlers.DeleteWebhook)
+			projects.GET("/:id/webhooks/:hookId/deliveries", middleware.AuthMiddleware(), handlers.GetWebhookDeliveries)
+			projects.POST("/:id/webhooks/:hookId/deliveries/:deliveryId/redeliver", middleware.AuthMiddleware(), handlers.RedeliverWebhook)
+			projects.PUT("/:id/questions", middleware.AuthMiddleware(), handlers.UpdateProjectQuestions)
+			projects.POST("/:id/positions", middleware.AuthMiddleware(), handlers.CreatePosition)
+			projects.PUT("/:id/positions/:positionId", middleware.AuthMiddleware(), handlers.UpdatePosition)
+			projects.DELETE("/:id/positions/:positionId", middleware.AuthMiddleware(), handlers.DeletePosition)
+			projects.POST("/:id/status", middleware.AuthMiddleware(), handlers.UpdateProjectStatus)
+			projects.GET("/:id/history", middleware.AuthMiddleware(), handlers.GetProjectHistory)
+
+			// Ownership transfer routes
+			projects.POST("/:id/transfer", middleware.AuthMiddleware(), handlers.RequestOwnershipTransfer)
+			projects.GET("/:id/transfer", middleware.AuthMiddleware(), handlers.GetOwnershipTransfer)
+			projects.DELETE("/:id/transfer", middleware.AuthMiddleware(), handlers.CancelOwnershipTransfer)
+			projects.POST("/:id/transfer/accept", middleware.AuthMiddleware(), handlers.AcceptOwnershipTransfer)
+			projects.POST("/:id/transfer/decline", middleware.AuthMiddleware(), handlers.DeclineOwnershipTransfer)
 
 			// Member routes
-			projects.POST("/:id/apply", middleware.AuthMiddleware(), handlers.ApplyToProject)
+			projects.POST("/:id/apply", middleware.AuthMiddleware(), middleware.RequireVerifiedEmail(), handlers.ApplyToProject)
 			projects.POST("/:id/accept/:userId", middleware.AuthMiddleware(), handlers.AcceptMember)
+			projects.POST("/:id/reject/:userId", middleware.AuthMiddleware(), handlers.RejectMember)
+			projects.POST("/:id/remove/:userId", middleware.AuthMiddleware(), handlers.RemoveMember)
+			projects.POST("/:id/withdraw", middleware.AuthMiddleware(), handlers.WithdrawApplication)
+			projects.POST("/:id/leave", middleware.AuthMiddleware(), handlers.LeaveProject)
+			projects.PUT("/:id/members/:userId/role", middleware.AuthMiddleware(), handlers.UpdateMemberRole)
+			projects.GET("/:id/applications", middleware.AuthMiddleware(), handlers.GetProjectApplications)
+			projects.GET("/:id/candidates", middleware.AuthMiddleware(), handlers.GetProjectCandidates)
+
+			// Invitation routes
+			projects.POST("/:id/invitations", middleware.AuthMiddleware(), handlers.CreateInvitation)
+			projects.GET("/:id/invitations", middleware.AuthMiddleware(), handlers.GetProjectInvitations)
+			projects.DELETE("/:id/invitations/:invitationId", middleware.AuthMiddleware(), handlers.RevokeInvitation)
+			projects.POST("/:id/invite-links", middleware.AuthMiddleware(), handlers.CreateInviteLink)
+			projects.GET("/:id/invite-links", middleware.AuthMiddleware(), handlers.GetInviteLinks)
+			projects.DELETE("/:id/invite-links/:linkId", middleware.AuthMiddleware(), handlers.RevokeInviteLink)
 
 			// Message routes
 			projects.GET("/:id/messages", middleware.AuthMiddleware(), handlers.GetProjectMessages)
-			projects.POST("/:id/messages", middleware.AuthMiddleware(), handlers.SendMessage)
+			projects.POST("/:id/messages", middleware.AuthMiddleware(), middleware.RequireVerifiedEmail(), handlers.SendMessage)
+			projects.PATCH("/:id/messages/:msgId", middleware.AuthMiddleware(), handlers.UpdateMessage)
+			projects.DELETE("/:id/messages/:msgId", middleware.AuthMiddleware(), handlers.DeleteMessage)
+			projects.GET("/:id/messages/:msgId/edits", middleware.AuthMiddleware(), handlers.GetMessageEdits)
+			projects.GET("/:id/messages/:msgId/thread", middleware.AuthMiddleware(), handlers.GetMessageThread)
+			projects.POST("/:id/messages/:msgId/reactions", middleware.AuthMiddleware(), handlers.AddReaction)
+			projects.DELETE("/:id/messages/:msgId/reactions/:emoji", middleware.AuthMiddleware(), handlers.RemoveReaction)
+			projects.POST("/:id/messages/:msgId/pin", middleware.AuthMiddleware(), handlers.PinMessage)
+			projects.DELETE("/:id/messages/:msgId/pin", middleware.AuthMiddleware(), handlers.UnpinMessage)
+			projects.GET("/:id/ws", middleware.WebSocketAuthMiddleware(), handlers.ProjectChatSocket)
+		}
+
+		// Invite link routes
+		invites := api.Group("/invites")
+		{
+			invites.GET("/:token", handlers.GetInvite)
+			invites.POST("/:token/join", middleware.AuthMiddleware(), middleware.RequireVerifiedEmail(), handlers.JoinByInviteLink)
 		}
 	}
 
@@ -0,0 +1,168 @@
+package skills
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	"project-exchange/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// builtinAliases maps common alternative spellings to canonical skill names.
+// Canonical names map to themselves implicitly.
+var builtinAliases = map[string]string{
+	"golang":       "Go",
+	"go lang":      "Go",
+	"js":           "JavaScript",
+	"ecmascript":   "JavaScript",
+	"ts":           "TypeScript",
+	"node":         "Node.js",
+	"nodejs":       "Node.js",
+	"node js":      "Node.js",
+	"react.js":     "React",
+	"reactjs":      "React",
+	"react native": "React Native",
+	"vue.js":       "Vue",
+	"vuejs":        "Vue",
+	"next":         "Next.js",
+	"nextjs":       "Next.js",
+	"py":           "Python",
+	"python3":      "Python",
+	"postgres":     "PostgreSQL",
+	"postgresql":   "PostgreSQL",
+	"psql":         "PostgreSQL",
+	"mysql":        "MySQL",
+	"mongo":        "MongoDB",
+	"k8s":          "Kubernetes",
+	"csharp":       "C#",
+	"c sharp":      "C#",
+	"cpp":          "C++",
+	"cplusplus":    "C++",
+	"ml":           "Machine Learning",
+	"ai":           "Artificial Intelligence",
+	"ui/ux":        "UI/UX Design",
+	"ux/ui":        "UI/UX Design",
+	"ux":           "UI/UX Design",
+	"ui":           "UI/UX Design",
+	"figma design": "Figma",
+	"tailwindcss":  "Tailwind CSS",
+	"tailwind":     "Tailwind CSS",
+	"html5":        "HTML",
+	"css3":         "CSS",
+}
+
+// MaxNameLength is the longest skill name, in characters, users may add.
+const MaxNameLength = 50
+
+// Clean returns the form a skill name is stored in: control characters
+// dropped and surrounding and repeated whitespace removed.
+func Clean(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsSpace(r):
+			return ' '
+		case !unicode.IsPrint(r):
+			return -1
+		}
+		return r
+	}, name)
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// Normalize returns the lookup key for a skill name: the cleaned name
+// lower-cased.
+func Normalize(name string) string {
+	return strings.ToLower(Clean(name))
+}
+
+// ParseList splits a free-form skills string such as "Go, React; SQL" into
+// trimmed names, dropping empties.
+func ParseList(s string) []string {
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ';' || r == '\n'
+	})
+
+	names := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if name := Clean(part); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Seed makes sure every built-in canonical skill and alias exists.
+func Seed(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for alias, canonical := range builtinAliases {
+			skill, err := findOrCreate(tx, canonical)
+			if err != nil {
+				return err
+			}
+
+			res := tx.Where("alias = ?", alias).Limit(1).Find(&models.SkillAlias{})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				if err := tx.Create(&models.SkillAlias{Alias: alias, SkillID: skill.ID}).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Lookup finds the skill a name or alias refers to without creating one. It
+// returns gorm.ErrRecordNotFound for unknown names.
+func Lookup(db *gorm.DB, name string) (models.Skill, error) {
+	key := Normalize(name)
+
+	// Find rather than First: unknown names are routine and not worth logging
+	var skill models.Skill
+	res := db.Where("slug = ?", key).Limit(1).Find(&skill)
+	if res.Error != nil || res.RowsAffected > 0 {
+		return skill, res.Error
+	}
+
+	var alias models.SkillAlias
+	res = db.Preload("Skill").Where("alias = ?", key).Limit(1).Find(&alias)
+	if res.Error != nil {
+		return skill, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return skill, gorm.ErrRecordNotFound
+	}
+	return alias.Skill, nil
+}
+
+// Resolve returns the skill a name or alias refers to, adding unknown skills
+// to the taxonomy.
+func Resolve(db *gorm.DB, name string) (models.Skill, error) {
+	skill, err := Lookup(db, name)
+	if err == nil {
+		return skill, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return skill, err
+	}
+	return findOrCreate(db, Clean(name))
+}
+
+// Canonical returns the canonical spelling of a skill name, or the trimmed
+// name itself when the taxonomy does not know it.
+func Canonical(db *gorm.DB, name string) string {
+	if skill, err := Lookup(db, name); err == nil {
+		return skill.Name
+	}
+	return Clean(name)
+}
+
+func findOrCreate(db *gorm.DB, name string) (models.Skill, error) {
+	skill := models.Skill{Name: name, Slug: Normalize(name)}
+	err := db.Where(models.Skill{Slug: skill.Slug}).FirstOrCreate(&skill).Error
+	return skill, err
+}
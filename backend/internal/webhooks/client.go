@@ -0,0 +1,108 @@
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+
+	"project-exchange/internal/config"
+)
+
+// ErrLocalAddress is returned when a webhook points at a host on the server's
+// own network.
+var ErrLocalAddress = errors.New("Webhooks may not target loopback, private or link-local addresses")
+
+// Ranges that IsPrivate and friends don't cover but that still reach internal
+// services, such as carrier-grade NAT used for some cloud metadata endpoints.
+var blockedNets = mustParseCIDRs("0.0.0.0/8", "100.64.0.0/10")
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets[i] = n
+	}
+	return nets
+}
+
+// AllowLocalHosts reports whether WEBHOOK_ALLOW_LOCAL lets webhooks target
+// local and private addresses, which is only meant for development.
+func AllowLocalHosts() bool {
+	return config.Bool("WEBHOOK_ALLOW_LOCAL", false)
+}
+
+// IsLocalIP reports whether ip belongs to the server's own or a private
+// network.
+func IsLocalIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return true
+	}
+	for _, n := range blockedNets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckHost rejects webhook hosts that are obviously local: localhost names
+// and local IP literals. Names are checked again against the addresses they
+// resolve to when a delivery connects.
+func CheckHost(host string) error {
+	if AllowLocalHosts() {
+		return nil
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrLocalAddress
+	}
+	if ip := net.ParseIP(host); ip != nil && IsLocalIP(ip) {
+		return ErrLocalAddress
+	}
+	return nil
+}
+
+// NewClient returns the HTTP client deliveries are posted with. Unless
+// allowLocal is set it refuses to connect to local addresses; the check runs
+// on the address actually dialled, after DNS resolution, so a name that
+// re-resolves to an internal address is caught too. Redirects are never
+// followed, and a 3xx response counts as a failed attempt.
+func NewClient(timeout time.Duration, allowLocal bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowLocal {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || IsLocalIP(ip) {
+				return fmt.Errorf("%w: %s", ErrLocalAddress, host)
+			}
+			return nil
+		}
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			// No proxy: the address check must see the receiver itself
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
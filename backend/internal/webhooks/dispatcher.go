@@ -0,0 +1,190 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"project-exchange/internal/config"
+	"project-exchange/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	dueBatchSize  = 100
+	maxErrorBytes = 500
+)
+
+// Dispatcher posts queued deliveries and schedules retries with exponential
+// backoff: attempt n+1 waits RetryBase * 2^(n-1). A delivery fails for good
+// after MaxAttempts. Client should come from NewClient so deliveries can't
+// reach internal services.
+type Dispatcher struct {
+	DB           *gorm.DB
+	Client       *http.Client
+	MaxAttempts  int
+	RetryBase    time.Duration
+	PollInterval time.Duration
+
+	wake chan struct{}
+}
+
+func NewDispatcher(db *gorm.DB, client *http.Client) *Dispatcher {
+	return &Dispatcher{
+		DB:           db,
+		Client:       client,
+		MaxAttempts:  6,
+		RetryBase:    30 * time.Second,
+		PollInterval: 2 * time.Second,
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+var defaultDispatcher *Dispatcher
+
+// InitDispatcher configures the process-wide dispatcher from the environment
+// and starts it in the background.
+func InitDispatcher(db *gorm.DB) {
+	d := NewDispatcher(db, NewClient(config.Duration("WEBHOOK_TIMEOUT", 10*time.Second), AllowLocalHosts()))
+	d.MaxAttempts = config.Int("WEBHOOK_MAX_ATTEMPTS", d.MaxAttempts)
+	d.RetryBase = config.Duration("WEBHOOK_RETRY_BASE", d.RetryBase)
+	d.PollInterval = config.Duration("WEBHOOK_POLL_INTERVAL", d.PollInterval)
+	defaultDispatcher = d
+
+	go d.Run(context.Background())
+}
+
+func GetDispatcher() *Dispatcher {
+	return defaultDispatcher
+}
+
+// Wake makes a running dispatcher look for due deliveries right away.
+func (d *Dispatcher) Wake() {
+	if d == nil {
+		return
+	}
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run delivers due deliveries every PollInterval, or sooner when woken, until
+// ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.DeliverDue(ctx); err != nil {
+			log.Println("Failed to deliver webhooks:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// DeliverDue attempts every pending delivery whose next attempt is due.
+func (d *Dispatcher) DeliverDue(ctx context.Context) error {
+	var due []models.WebhookDelivery
+	err := d.DB.Where("status = ? AND next_attempt_at <= ?", models.DeliveryStatusPending, time.Now()).
+		Order("next_attempt_at").Limit(dueBatchSize).
+		Find(&due).Error
+	if err != nil {
+		return err
+	}
+
+	for i := range due {
+		if err := d.Deliver(ctx, &due[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Deliver makes one attempt at a delivery and records the outcome. The
+// returned error is about storing the outcome; a failed attempt is not an
+// error.
+func (d *Dispatcher) Deliver(ctx context.Context, delivery *models.WebhookDelivery) error {
+	var hook models.Webhook
+	if err := d.DB.First(&hook, delivery.WebhookID).Error; err != nil {
+		return d.finish(delivery, models.DeliveryStatusFailed, 0, "Webhook no longer exists")
+	}
+
+	status, attemptErr := d.post(ctx, hook, delivery)
+	delivery.Attempts++
+
+	switch {
+	case attemptErr == nil:
+		return d.finish(delivery, models.DeliveryStatusSucceeded, status, "")
+	case delivery.Attempts >= d.MaxAttempts:
+		return d.finish(delivery, models.DeliveryStatusFailed, status, attemptErr.Error())
+	default:
+		next := time.Now().Add(d.RetryBase << (delivery.Attempts - 1))
+		delivery.NextAttemptAt = &next
+		return d.finish(delivery, models.DeliveryStatusPending, status, attemptErr.Error())
+	}
+}
+
+// post sends the delivery and returns the response status. Anything but a
+// 2xx response is an error.
+func (d *Dispatcher) post(ctx context.Context, hook models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "project-exchange-webhooks")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(HeaderSignature, Sign(hook.Secret, body))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBytes))
+		return resp.StatusCode, fmt.Errorf("Receiver responded %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
+
+func (d *Dispatcher) finish(delivery *models.WebhookDelivery, status string, responseStatus int, lastError string) error {
+	now := time.Now()
+	if len(lastError) > maxErrorBytes {
+		lastError = lastError[:maxErrorBytes]
+	}
+
+	delivery.Status = status
+	delivery.LastAttemptAt = &now
+	delivery.ResponseStatus = responseStatus
+	delivery.LastError = lastError
+	if status != models.DeliveryStatusPending {
+		delivery.NextAttemptAt = nil
+	}
+
+	return d.DB.Model(delivery).Updates(map[string]interface{}{
+		"status":          delivery.Status,
+		"attempts":        delivery.Attempts,
+		"next_attempt_at": delivery.NextAttemptAt,
+		"last_attempt_at": delivery.LastAttemptAt,
+		"response_status": delivery.ResponseStatus,
+		"last_error":      delivery.LastError,
+	}).Error
+}
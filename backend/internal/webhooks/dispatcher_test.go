@@ -0,0 +1,258 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"project-exchange/internal/models"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const testSecret = "test-secret"
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// One connection, or each one would get its own in-memory database
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	if err := db.AutoMigrate(&models.Webhook{}, &models.WebhookDelivery{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// enqueueTo registers a hook for url and queues one message.posted event for
+// it, returning the delivery.
+func enqueueTo(t *testing.T, db *gorm.DB, url string) models.WebhookDelivery {
+	t.Helper()
+	hook := models.Webhook{
+		ProjectID: 1,
+		URL:       url,
+		Secret:    testSecret,
+		Events:    models.StringList{models.WebhookEventMessagePosted},
+		Active:    true,
+	}
+	if err := db.Create(&hook).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Enqueue(db, 1, models.WebhookEventMessagePosted, map[string]string{"content": "hello"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var delivery models.WebhookDelivery
+	if err := db.Where("webhook_id = ?", hook.ID).First(&delivery).Error; err != nil {
+		t.Fatal(err)
+	}
+	return delivery
+}
+
+func reload(t *testing.T, db *gorm.DB, delivery models.WebhookDelivery) models.WebhookDelivery {
+	t.Helper()
+	if err := db.First(&delivery, delivery.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	return delivery
+}
+
+func TestDeliverSignsPayload(t *testing.T) {
+	db := newTestDB(t)
+
+	var got struct {
+		signature, event string
+		payload          Payload
+		body             []byte
+	}
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.body, _ = io.ReadAll(r.Body)
+		got.signature = r.Header.Get(HeaderSignature)
+		got.event = r.Header.Get(HeaderEvent)
+		json.Unmarshal(got.body, &got.payload)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	delivery := enqueueTo(t, db, receiver.URL)
+	d := NewDispatcher(db, NewClient(5*time.Second, true))
+	if err := d.DeliverDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := Sign(testSecret, got.body); got.signature != want {
+		t.Errorf("signature = %q, want %q", got.signature, want)
+	}
+	if got.event != models.WebhookEventMessagePosted || got.payload.Event != models.WebhookEventMessagePosted {
+		t.Errorf("event header %q, payload event %q", got.event, got.payload.Event)
+	}
+	if got.payload.ProjectID != 1 {
+		t.Errorf("payload project_id = %d, want 1", got.payload.ProjectID)
+	}
+
+	delivery = reload(t, db, delivery)
+	if delivery.Status != models.DeliveryStatusSucceeded || delivery.Attempts != 1 || delivery.ResponseStatus != http.StatusNoContent {
+		t.Errorf("delivery = %s after %d attempts with %d, want succeeded after 1 with 204",
+			delivery.Status, delivery.Attempts, delivery.ResponseStatus)
+	}
+}
+
+func TestDeliverRetriesWithBackoff(t *testing.T) {
+	db := newTestDB(t)
+
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "try later", http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	delivery := enqueueTo(t, db, receiver.URL)
+	d := NewDispatcher(db, NewClient(5*time.Second, true))
+	d.MaxAttempts = 3
+	d.RetryBase = time.Minute
+
+	for attempt := 1; attempt <= d.MaxAttempts; attempt++ {
+		before := time.Now()
+		if err := d.Deliver(context.Background(), &delivery); err != nil {
+			t.Fatal(err)
+		}
+		delivery = reload(t, db, delivery)
+
+		if delivery.Attempts != attempt || delivery.ResponseStatus != http.StatusInternalServerError {
+			t.Fatalf("attempt %d: attempts = %d, response = %d", attempt, delivery.Attempts, delivery.ResponseStatus)
+		}
+		if !strings.Contains(delivery.LastError, "500") {
+			t.Errorf("attempt %d: last error %q does not mention the status", attempt, delivery.LastError)
+		}
+
+		if attempt < d.MaxAttempts {
+			if delivery.Status != models.DeliveryStatusPending || delivery.NextAttemptAt == nil {
+				t.Fatalf("attempt %d: status %s, want pending with a next attempt", attempt, delivery.Status)
+			}
+			wait := delivery.NextAttemptAt.Sub(before)
+			want := d.RetryBase << (attempt - 1)
+			if wait < want || wait > want+5*time.Second {
+				t.Errorf("attempt %d: retry in %s, want %s", attempt, wait, want)
+			}
+		}
+	}
+
+	if delivery.Status != models.DeliveryStatusFailed || delivery.NextAttemptAt != nil {
+		t.Errorf("status %s after the last attempt, want failed with no next attempt", delivery.Status)
+	}
+	if n := calls.Load(); n != int32(d.MaxAttempts) {
+		t.Errorf("receiver called %d times, want %d", n, d.MaxAttempts)
+	}
+}
+
+func TestDeliverRefusesLocalAddresses(t *testing.T) {
+	db := newTestDB(t)
+
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer receiver.Close()
+
+	delivery := enqueueTo(t, db, receiver.URL)
+	d := NewDispatcher(db, NewClient(5*time.Second, false))
+	if err := d.Deliver(context.Background(), &delivery); err != nil {
+		t.Fatal(err)
+	}
+
+	delivery = reload(t, db, delivery)
+	if calls.Load() != 0 {
+		t.Error("receiver on a loopback address was called")
+	}
+	if !strings.Contains(delivery.LastError, ErrLocalAddress.Error()) {
+		t.Errorf("last error = %q, want the local address error", delivery.LastError)
+	}
+}
+
+func TestDeliverDoesNotFollowRedirects(t *testing.T) {
+	db := newTestDB(t)
+
+	var followed atomic.Bool
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		followed.Store(true)
+	}))
+	defer target.Close()
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer receiver.Close()
+
+	delivery := enqueueTo(t, db, receiver.URL)
+	d := NewDispatcher(db, NewClient(5*time.Second, true))
+	if err := d.Deliver(context.Background(), &delivery); err != nil {
+		t.Fatal(err)
+	}
+
+	delivery = reload(t, db, delivery)
+	if followed.Load() {
+		t.Error("redirect was followed")
+	}
+	if delivery.Status != models.DeliveryStatusPending || delivery.ResponseStatus != http.StatusTemporaryRedirect {
+		t.Errorf("delivery %s with %d, want pending with 307", delivery.Status, delivery.ResponseStatus)
+	}
+}
+
+func TestIsLocalIP(t *testing.T) {
+	cases := map[string]bool{
+		"127.0.0.1":        true,
+		"10.1.2.3":         true,
+		"172.16.0.1":       true,
+		"192.168.1.1":      true,
+		"169.254.169.254":  true,
+		"100.100.100.200":  true,
+		"0.0.0.0":          true,
+		"::1":              true,
+		"fe80::1":          true,
+		"fd00::1":          true,
+		"::ffff:127.0.0.1": true,
+		"93.184.216.34":    false,
+		"2606:4700::1111":  false,
+	}
+	for addr, want := range cases {
+		if got := IsLocalIP(net.ParseIP(addr)); got != want {
+			t.Errorf("IsLocalIP(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestCheckHost(t *testing.T) {
+	t.Setenv("WEBHOOK_ALLOW_LOCAL", "false")
+	for _, host := range []string{"localhost", "api.localhost", "127.0.0.1", "169.254.169.254", "::1"} {
+		if err := CheckHost(host); !errors.Is(err, ErrLocalAddress) {
+			t.Errorf("CheckHost(%q) = %v, want ErrLocalAddress", host, err)
+		}
+	}
+	if err := CheckHost("hooks.example.com"); err != nil {
+		t.Errorf("CheckHost(hooks.example.com) = %v", err)
+	}
+
+	t.Setenv("WEBHOOK_ALLOW_LOCAL", "true")
+	if err := CheckHost("127.0.0.1"); err != nil {
+		t.Errorf("CheckHost(127.0.0.1) with local hosts allowed = %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package webhooks
+
+import (
+	"time"
+
+	"project-exchange/internal/models"
+)
+
+// Payload data is built from explicit structs rather than the models so that
+// nothing private, such as email addresses or cover letters, reaches a
+// third-party receiver when a model grows a field.
+
+// ProjectData is the data of project.updated events.
+type ProjectData struct {
+	ID          uint      `json:"id"`
+	OwnerID     uint      `json:"owner_id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Category    string    `json:"category"`
+	Level       string    `json:"level"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// MemberData is the data of member.applied and member.accepted events.
+type MemberData struct {
+	ID         uint                      `json:"id"`
+	ProjectID  uint                      `json:"project_id"`
+	Status     string                    `json:"status"`
+	Role       string                    `json:"role"`
+	PositionID *uint                     `json:"position_id"`
+	Position   *models.ProjectPosition   `json:"position,omitempty"`
+	User       models.PublicUserResponse `json:"user"`
+	CreatedAt  time.Time                 `json:"created_at"`
+}
+
+// MessageData is the data of message.posted events.
+type MessageData struct {
+	ID        uint                      `json:"id"`
+	ProjectID uint                      `json:"project_id"`
+	ReplyToID *uint                     `json:"reply_to_id"`
+	Content   string                    `json:"content"`
+	User      models.PublicUserResponse `json:"user"`
+	CreatedAt time.Time                 `json:"created_at"`
+}
+
+func NewProjectData(p models.Project) ProjectData {
+	return ProjectData{
+		ID:          p.ID,
+		OwnerID:     p.OwnerID,
+		Title:       p.Title,
+		Description: p.Description,
+		Category:    p.Category,
+		Level:       p.Level,
+		Status:      p.Status,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+	}
+}
+
+// NewMemberData expects the member's User and Position to be loaded.
+func NewMemberData(m models.ProjectMember) MemberData {
+	return MemberData{
+		ID:         m.ID,
+		ProjectID:  m.ProjectID,
+		Status:     m.Status,
+		Role:       m.Role,
+		PositionID: m.PositionID,
+		Position:   m.Position,
+		User:       m.User.ToPublicResponse(),
+		CreatedAt:  m.CreatedAt,
+	}
+}
+
+// NewMessageData expects the message's User to be loaded.
+func NewMessageData(m models.Message) MessageData {
+	return MessageData{
+		ID:        m.ID,
+		ProjectID: m.ProjectID,
+		ReplyToID: m.ReplyToID,
+		Content:   m.Content,
+		User:      m.User.ToPublicResponse(),
+		CreatedAt: m.CreatedAt,
+	}
+}
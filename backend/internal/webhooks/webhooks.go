@@ -0,0 +1,126 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"project-exchange/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Headers sent with every delivery.
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderSignature = "X-Webhook-Signature" // "sha256=" + hex HMAC of the body
+)
+
+// Payload is the JSON body posted to webhooks.
+type Payload struct {
+	Event      string      `json:"event"`
+	ProjectID  uint        `json:"project_id"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// Sign returns the signature header value for body. Receivers recompute it
+// with the hook's secret and compare in constant time.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Enqueue queues the event for every active webhook of the project that
+// subscribes to it. Pass the transaction that makes the change so the
+// deliveries only exist if it commits; the dispatcher picks them up from
+// there.
+func Enqueue(tx *gorm.DB, projectID uint, event string, data interface{}) error {
+	var hooks []models.Webhook
+	if err := tx.Where("project_id = ? AND active = ?", projectID, true).Find(&hooks).Error; err != nil {
+		return err
+	}
+
+	var payload []byte
+	now := time.Now()
+	for _, hook := range hooks {
+		if !hook.Subscribes(event) {
+			continue
+		}
+
+		if payload == nil {
+			var err error
+			payload, err = json.Marshal(Payload{Event: event, ProjectID: projectID, OccurredAt: now, Data: data})
+			if err != nil {
+				return err
+			}
+		}
+
+		delivery := models.WebhookDelivery{
+			WebhookID:     hook.ID,
+			Event:         event,
+			Payload:       string(payload),
+			Status:        models.DeliveryStatusPending,
+			NextAttemptAt: &now,
+		}
+		if err := tx.Create(&delivery).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RedactMessage swaps the removed message's stub into the stored payloads of
+// its message.posted deliveries, so the original text is neither kept nor
+// sent again, and gives up on the deliveries still pending. Call it with the
+// transaction that removes the message.
+func RedactMessage(tx *gorm.DB, message models.Message) error {
+	var deliveries []models.WebhookDelivery
+	err := tx.Where("event = ? AND json_extract(payload, '$.data.id') = ?", models.WebhookEventMessagePosted, message.ID).
+		Where("webhook_id IN (?)", tx.Model(&models.Webhook{}).Select("id").Where("project_id = ?", message.ProjectID)).
+		Find(&deliveries).Error
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		var payload Payload
+		if err := json.Unmarshal([]byte(delivery.Payload), &payload); err != nil {
+			return err
+		}
+		payload.Data = NewMessageData(message)
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{"payload": string(body)}
+		if delivery.Status == models.DeliveryStatusPending {
+			updates["status"] = models.DeliveryStatusFailed
+			updates["next_attempt_at"] = nil
+			updates["last_error"] = "Message was removed"
+		}
+		if err := tx.Model(&delivery).Updates(updates).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MessageRemoved reports whether a message.posted delivery is about a message
+// that has since been removed.
+func MessageRemoved(db *gorm.DB, delivery models.WebhookDelivery) (bool, error) {
+	var data MessageData
+	if err := json.Unmarshal([]byte(delivery.Payload), &Payload{Data: &data}); err != nil {
+		return false, err
+	}
+
+	var kept int64
+	err := db.Model(&models.Message{}).Where("id = ? AND removed_at IS NULL", data.ID).Count(&kept).Error
+	return kept == 0, err
+}
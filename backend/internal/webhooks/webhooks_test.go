@@ -0,0 +1,117 @@
+package webhooks
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"project-exchange/internal/models"
+)
+
+func TestRedactMessage(t *testing.T) {
+	db := newTestDB(t)
+	if err := db.AutoMigrate(&models.User{}, &models.Message{}); err != nil {
+		t.Fatal(err)
+	}
+
+	hook := models.Webhook{
+		ProjectID: 1,
+		URL:       "https://example.com/hook",
+		Secret:    testSecret,
+		Events:    models.StringList{models.WebhookEventMessagePosted},
+		Active:    true,
+	}
+	if err := db.Create(&hook).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	author := models.User{Name: "Author", Email: "author@example.com", PasswordHash: "x"}
+	if err := db.Create(&author).Error; err != nil {
+		t.Fatal(err)
+	}
+	messages := []models.Message{
+		{ProjectID: 1, UserID: author.ID, User: author, Content: "secret plans"},
+		{ProjectID: 1, UserID: author.ID, User: author, Content: "lunch?"},
+	}
+	for i := range messages {
+		if err := db.Omit("User").Create(&messages[i]).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := Enqueue(db, 1, models.WebhookEventMessagePosted, NewMessageData(messages[i])); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := db.Order("id").Find(&deliveries).Error; err != nil {
+		t.Fatal(err)
+	}
+	// The first one was already sent
+	err := db.Model(&deliveries[0]).Update("status", models.DeliveryStatusSucceeded).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	removed := messages[0]
+	now := time.Now()
+	removed.Content = models.MessageRemovedContent
+	removed.RemovedAt = &now
+	if err := db.Omit("User").Save(&removed).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := RedactMessage(db, removed); err != nil {
+		t.Fatal(err)
+	}
+
+	redacted := reload(t, db, deliveries[0])
+	if strings.Contains(redacted.Payload, "secret plans") || !strings.Contains(redacted.Payload, models.MessageRemovedContent) {
+		t.Errorf("redacted payload = %s", redacted.Payload)
+	}
+	if redacted.Status != models.DeliveryStatusSucceeded {
+		t.Errorf("sent delivery status = %q, want it kept", redacted.Status)
+	}
+
+	kept := reload(t, db, deliveries[1])
+	if kept.Payload != deliveries[1].Payload || kept.Status != models.DeliveryStatusPending {
+		t.Errorf("other message's delivery changed: %+v", kept)
+	}
+
+	for i, want := range []bool{true, false} {
+		got, err := MessageRemoved(db, reload(t, db, deliveries[i]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("delivery %d: MessageRemoved = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestRedactMessageCancelsPendingDeliveries(t *testing.T) {
+	db := newTestDB(t)
+	if err := db.AutoMigrate(&models.User{}, &models.Message{}); err != nil {
+		t.Fatal(err)
+	}
+
+	message := models.Message{ProjectID: 1, UserID: 1, Content: "oops"}
+	if err := db.Create(&message).Error; err != nil {
+		t.Fatal(err)
+	}
+	delivery := enqueueTo(t, db, "https://example.com/hook")
+	// enqueueTo's payload is not about a message; point one at this one
+	if err := Enqueue(db, 1, models.WebhookEventMessagePosted, NewMessageData(message)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RedactMessage(db, message); err != nil {
+		t.Fatal(err)
+	}
+
+	var pending []models.WebhookDelivery
+	if err := db.Where("status = ?", models.DeliveryStatusPending).Find(&pending).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].ID != delivery.ID {
+		t.Fatalf("pending deliveries = %+v, want only the unrelated one", pending)
+	}
+}